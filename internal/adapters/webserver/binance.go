@@ -0,0 +1,1042 @@
+package webserver
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/entities"
+)
+
+// Binance compatible api: the spot endpoints are served under /api/v3 and the futures endpoints under /fapi,
+// the request parameters, the responses and the error codes follow Binance so the bots can be pointed here unchanged.
+// The order id is the numeric id of the order, the client order id is the one the order was placed with or the order uid.
+
+const (
+	binanceCodeUnknown             = -1000
+	binanceCodeIllegalParameter    = -1100
+	binanceCodeMandatoryParameter  = -1102
+	binanceCodeInvalidTimestamp    = -1021
+	binanceCodeInvalidSignature    = -1022
+	binanceCodeBadRecvWindow       = -1131
+	binanceCodeFilterFailure       = -1013
+	binanceCodeInvalidTimeInForce  = -1115
+	binanceCodeInvalidOrderType    = -1116
+	binanceCodeInvalidSide         = -1117
+	binanceCodeInvalidSymbol       = -1121
+	binanceCodeNewOrderRejected    = -2010
+	binanceCodeCancelRejected      = -2011
+	binanceCodeNoSuchOrder         = -2013
+	binanceCodeRejectedMBXKey      = -2015
+	binanceCodeMarginInsufficient  = -2019
+	binanceCodeWouldTrigger        = -2021
+	binanceCodeInvalidLeverage     = -4028
+	binanceCodeInvalidPositionSide = -4061
+	binanceCodeOpenOrdersExists    = -4067
+	binanceCodePositionExists      = -4068
+	binanceCodeFOKRejected         = -5021
+	binanceCodeGTXRejected         = -5022
+
+	binanceOpenOrdersLimit = 1000
+)
+
+var (
+	errBinanceOrderIDRequired   = errors.New("Either orderId or origClientOrderId must be sent")
+	errBinanceClientOrderID     = errors.New("Illegal characters found in parameter 'newClientOrderId'")
+	errBinanceDuplicateOrder    = errors.New("Duplicate order sent.")
+	binanceClientOrderIDPattern = regexp.MustCompile(`^[.A-Z:/a-z0-9_-]{1,36}$`)
+)
+
+// binanceErrorCodes maps the application errors to the Binance error codes of the spot and the futures api
+var binanceErrorCodes = []struct {
+	err     error
+	spot    int
+	futures int
+}{
+	{apperror.ErrSymbolIsNotValid, binanceCodeInvalidSymbol, binanceCodeInvalidSymbol},
+	{apperror.ErrOrderTypeNotValid, binanceCodeInvalidOrderType, binanceCodeInvalidOrderType},
+	{apperror.ErrOrderSideIsNotValid, binanceCodeInvalidSide, binanceCodeInvalidSide},
+	{apperror.ErrTimeInForceIsNotValid, binanceCodeInvalidTimeInForce, binanceCodeInvalidTimeInForce},
+	{apperror.ErrAmountIsNotValid, binanceCodeFilterFailure, binanceCodeFilterFailure},
+	{apperror.ErrAmountIsOutOfRange, binanceCodeFilterFailure, binanceCodeFilterFailure},
+	{apperror.ErrPriceIsNotValid, binanceCodeFilterFailure, binanceCodeFilterFailure},
+	{apperror.ErrStopPriceIsNotValid, binanceCodeFilterFailure, binanceCodeFilterFailure},
+	{apperror.ErrCallbackRateIsNotValid, binanceCodeFilterFailure, binanceCodeFilterFailure},
+	{apperror.ErrStopPriceWouldTrigger, binanceCodeWouldTrigger, binanceCodeWouldTrigger},
+	{apperror.ErrOrderWouldNotFill, binanceCodeNewOrderRejected, binanceCodeFOKRejected},
+	{apperror.ErrOrderWouldImmediatelyMatch, binanceCodeNewOrderRejected, binanceCodeGTXRejected},
+	{apperror.ErrInsufficientFunds, binanceCodeNewOrderRejected, binanceCodeMarginInsufficient},
+	{apperror.ErrBalanceNotFound, binanceCodeNewOrderRejected, binanceCodeMarginInsufficient},
+	{apperror.ErrOrderNotFound, binanceCodeNoSuchOrder, binanceCodeNoSuchOrder},
+	{apperror.ErrOrderAlreadyCancelled, binanceCodeCancelRejected, binanceCodeCancelRejected},
+	{apperror.ErrLeverageIsOutOfRange, binanceCodeInvalidLeverage, binanceCodeInvalidLeverage},
+	{apperror.ErrInvalidPositionSide, binanceCodeInvalidPositionSide, binanceCodeInvalidPositionSide},
+	{apperror.ErrOrderPositionModeIsNotValid, binanceCodeInvalidPositionSide, binanceCodeInvalidPositionSide},
+	{apperror.ErrOpenOrdersExists, binanceCodeOpenOrdersExists, binanceCodeOpenOrdersExists},
+	{apperror.ErrPositionExists, binanceCodePositionExists, binanceCodePositionExists},
+	{apperror.ErrAccountNotFound, binanceCodeRejectedMBXKey, binanceCodeRejectedMBXKey},
+	{apperror.ErrAccountResetting, binanceCodeNewOrderRejected, binanceCodeNewOrderRejected},
+	{errBinanceOrderIDRequired, binanceCodeMandatoryParameter, binanceCodeMandatoryParameter},
+	{errBinanceClientOrderID, binanceCodeIllegalParameter, binanceCodeIllegalParameter},
+	{errBinanceDuplicateOrder, binanceCodeNewOrderRejected, binanceCodeNewOrderRejected},
+	{apperror.ErrTimestampIsNotValid, binanceCodeMandatoryParameter, binanceCodeMandatoryParameter},
+	{apperror.ErrTimestampOutsideRecvWindow, binanceCodeInvalidTimestamp, binanceCodeInvalidTimestamp},
+	{apperror.ErrRecvWindowIsNotValid, binanceCodeBadRecvWindow, binanceCodeBadRecvWindow},
+	{apperror.ErrSignatureNotValid, binanceCodeInvalidSignature, binanceCodeInvalidSignature},
+	{apperror.ErrRequestReplayed, binanceCodeInvalidSignature, binanceCodeInvalidSignature},
+	{apperror.ErrTokenNotFound, binanceCodeRejectedMBXKey, binanceCodeRejectedMBXKey},
+	{apperror.ErrTokenExpired, binanceCodeRejectedMBXKey, binanceCodeRejectedMBXKey},
+	{apperror.ErrIPNotAllowed, binanceCodeRejectedMBXKey, binanceCodeRejectedMBXKey},
+	{apperror.ErrPermissionDenied, binanceCodeRejectedMBXKey, binanceCodeRejectedMBXKey},
+}
+
+func binanceErrorCode(exchange entities.Exchange, err error) int {
+	for _, e := range binanceErrorCodes {
+		if errors.Is(err, e.err) {
+			if exchange == entities.ExchangeFutures {
+				return e.futures
+			}
+			return e.spot
+		}
+	}
+
+	return binanceCodeUnknown
+}
+
+// binanceOrderTypes translates the Binance order types of both apis, the take profit orders have no counterpart
+var binanceOrderTypes = map[string]entities.OrderType{
+	"LIMIT":                entities.OrderTypeLimit,
+	"LIMIT_MAKER":          entities.OrderTypeLimit,
+	"MARKET":               entities.OrderTypeMarket,
+	"STOP_LOSS":            entities.OrderTypeStopMarket,
+	"STOP_LOSS_LIMIT":      entities.OrderTypeStopLimit,
+	"STOP_MARKET":          entities.OrderTypeStopMarket,
+	"STOP":                 entities.OrderTypeStopLimit,
+	"TRAILING_STOP_MARKET": entities.OrderTypeTrailingStop,
+}
+
+func (r *Routes) binanceRoutes(g *gin.Engine) {
+	spotTrade := r.binanceScopeMiddleware(entities.KeyScopeSpot)
+	futuresTrade := r.binanceScopeMiddleware(entities.KeyScopeFutures)
+
+	spot := g.Group("/api/v3")
+	spot.GET("/ping", r.getBinancePingHandler)
+	spot.GET("/time", r.getBinanceTimeHandler)
+	spot.GET("/exchangeInfo", r.getBinanceExchangeInfoHandler(entities.ExchangeSpot))
+	spot.GET("/ticker/price", r.getBinanceTickerPriceHandler(entities.ExchangeSpot))
+
+	spotPrivate := spot.Group("", r.authBinanceMiddleware())
+	spotPrivate.POST("/order", spotTrade, r.postBinanceOrderHandler(entities.ExchangeSpot))
+	spotPrivate.GET("/order", r.getBinanceOrderHandler(entities.ExchangeSpot))
+	spotPrivate.DELETE("/order", spotTrade, r.deleteBinanceOrderHandler(entities.ExchangeSpot))
+	spotPrivate.GET("/openOrders", r.getBinanceOpenOrdersHandler(entities.ExchangeSpot))
+	spotPrivate.GET("/account", r.getBinanceSpotAccountHandler)
+
+	futures := g.Group("/fapi")
+	futures.GET("/v1/ping", r.getBinancePingHandler)
+	futures.GET("/v1/time", r.getBinanceTimeHandler)
+	futures.GET("/v1/exchangeInfo", r.getBinanceExchangeInfoHandler(entities.ExchangeFutures))
+	futures.GET("/v1/ticker/price", r.getBinanceTickerPriceHandler(entities.ExchangeFutures))
+
+	futuresPrivate := futures.Group("", r.authBinanceMiddleware())
+	futuresPrivate.POST("/v1/order", futuresTrade, r.postBinanceOrderHandler(entities.ExchangeFutures))
+	futuresPrivate.GET("/v1/order", r.getBinanceOrderHandler(entities.ExchangeFutures))
+	futuresPrivate.DELETE("/v1/order", futuresTrade, r.deleteBinanceOrderHandler(entities.ExchangeFutures))
+	futuresPrivate.GET("/v1/openOrders", r.getBinanceOpenOrdersHandler(entities.ExchangeFutures))
+	futuresPrivate.GET("/v2/account", r.getBinanceFuturesAccountHandler)
+	futuresPrivate.GET("/v2/balance", r.getBinanceFuturesBalanceHandler)
+	futuresPrivate.GET("/v2/positionRisk", r.getBinancePositionRiskHandler)
+	futuresPrivate.POST("/v1/leverage", futuresTrade, r.postBinanceLeverageHandler)
+	futuresPrivate.POST("/v1/marginType", futuresTrade, r.postBinanceMarginTypeHandler)
+	futuresPrivate.GET("/v1/positionSide/dual", r.getBinancePositionSideHandler)
+	futuresPrivate.POST("/v1/positionSide/dual", futuresTrade, r.postBinancePositionSideHandler)
+}
+
+func (r *Routes) authBinanceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := r.authenticate(c, c.GetHeader("X-MBX-APIKEY"))
+		if err != nil {
+			if code := binanceErrorCode(entities.ExchangeSpot, err); code != binanceCodeUnknown {
+				binanceAbort(c, code, err.Error())
+				return
+			}
+			binanceAbort(c, binanceCodeRejectedMBXKey, "Invalid API-key, IP, or permissions for action.")
+			return
+		}
+
+		c.Set("accountUID", key.AccountUID)
+		c.Set("apiKey", key)
+
+		c.Next()
+	}
+}
+
+// binanceScopeMiddleware lets through the requests made with a key allowed the scope
+func (r *Routes) binanceScopeMiddleware(scope entities.KeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !keyHasScope(c, scope) {
+			binanceAbort(c, binanceCodeRejectedMBXKey, "Invalid API-key, IP, or permissions for action.")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func binanceAbort(c *gin.Context, code int, msg string) {
+	status := http.StatusBadRequest
+	if code == binanceCodeRejectedMBXKey {
+		status = http.StatusUnauthorized
+	}
+
+	c.AbortWithStatusJSON(status, BinanceError{
+		Code: code,
+		Msg:  msg,
+	})
+}
+
+func binanceError(c *gin.Context, exchange entities.Exchange, err error) {
+	binanceAbort(c, binanceErrorCode(exchange, err), err.Error())
+}
+
+func binanceAccountUID(c *gin.Context) (entities.AccountUID, bool) {
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		binanceAbort(c, binanceCodeRejectedMBXKey, "Token not found")
+		return "", false
+	}
+
+	return accountUID.(entities.AccountUID), true
+}
+
+func binanceDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// binanceSymbol finds the symbol of the Binance market id
+func (r *Routes) binanceSymbol(exchange entities.Exchange, id string) (entities.Symbol, error) {
+	markets, err := r.markets.GetMarkets(exchange.Name())
+	if err != nil {
+		return "", err
+	}
+
+	for _, market := range markets {
+		if market.ID == id {
+			return entities.Symbol(market.Symbol), nil
+		}
+	}
+
+	return "", apperror.ErrSymbolIsNotValid
+}
+
+// binanceSymbolID returns the Binance market id of the symbol
+func (r *Routes) binanceSymbolID(exchange entities.Exchange, symbol entities.Symbol) string {
+	if markets, err := r.markets.GetMarkets(exchange.Name()); err == nil {
+		if market, ok := markets[symbol.String()]; ok {
+			return market.ID
+		}
+	}
+
+	return strings.ReplaceAll(symbol.String(), "/", "")
+}
+
+func binanceOrderType(order *entities.Order) string {
+	spot := order.Exchange == entities.ExchangeSpot
+
+	switch order.Type {
+	case entities.OrderTypeLimit:
+		if spot && order.TimeInForce == entities.TimeInForceGTX {
+			return "LIMIT_MAKER"
+		}
+		return "LIMIT"
+	case entities.OrderTypeMarket:
+		return "MARKET"
+	case entities.OrderTypeStopMarket:
+		if spot {
+			return "STOP_LOSS"
+		}
+		return "STOP_MARKET"
+	case entities.OrderTypeStopLimit:
+		if spot {
+			return "STOP_LOSS_LIMIT"
+		}
+		return "STOP"
+	case entities.OrderTypeTrailingStop:
+		return "TRAILING_STOP_MARKET"
+	default:
+		return strings.ToUpper(string(order.Type))
+	}
+}
+
+func binanceOrderStatus(order *entities.Order) string {
+	switch order.Status {
+	case entities.OrderStatusNew, entities.OrderStatusPending:
+		return "NEW"
+	case entities.OrderStatusPartiallyFilled:
+		return "PARTIALLY_FILLED"
+	case entities.OrderStatusSuccess:
+		return "FILLED"
+	case entities.OrderStatusCancelled:
+		return "CANCELED"
+	case entities.OrderStatusFailed:
+		if order.TimeInForce != entities.TimeInForceGTC {
+			return "EXPIRED"
+		}
+		return "REJECTED"
+	default:
+		return strings.ToUpper(string(order.Status))
+	}
+}
+
+func binanceClientOrderID(order *entities.Order) string {
+	if order.ClientOrderID != "" {
+		return order.ClientOrderID
+	}
+
+	return order.OrderUID
+}
+
+func (r *Routes) binanceOrder(order *entities.Order) any {
+	symbol := r.binanceSymbolID(order.Exchange, order.Symbol)
+
+	if order.Exchange == entities.ExchangeSpot {
+		return BinanceSpotOrder{
+			Symbol:              symbol,
+			OrderID:             order.OrderID,
+			OrderListID:         -1,
+			ClientOrderID:       binanceClientOrderID(order),
+			TransactTime:        order.CreateTS,
+			Price:               binanceDecimal(order.Price),
+			OrigQty:             binanceDecimal(order.Amount),
+			ExecutedQty:         binanceDecimal(order.FilledAmount),
+			CummulativeQuoteQty: binanceDecimal(order.FilledAmount * order.AvgPrice),
+			Status:              binanceOrderStatus(order),
+			TimeInForce:         string(order.TimeInForce),
+			Type:                binanceOrderType(order),
+			Side:                strings.ToUpper(string(order.Side)),
+			StopPrice:           binanceDecimal(order.StopPrice),
+			Time:                order.CreateTS,
+			UpdateTime:          order.UpdateTS,
+			IsWorking:           !order.Status.IsFinal(),
+		}
+	}
+
+	result := BinanceFuturesOrder{
+		Symbol:        symbol,
+		OrderID:       order.OrderID,
+		ClientOrderID: binanceClientOrderID(order),
+		Price:         binanceDecimal(order.Price),
+		AvgPrice:      binanceDecimal(order.AvgPrice),
+		OrigQty:       binanceDecimal(order.Amount),
+		ExecutedQty:   binanceDecimal(order.FilledAmount),
+		CumQuote:      binanceDecimal(order.FilledAmount * order.AvgPrice),
+		Status:        binanceOrderStatus(order),
+		TimeInForce:   string(order.TimeInForce),
+		Type:          binanceOrderType(order),
+		OrigType:      binanceOrderType(order),
+		Side:          strings.ToUpper(string(order.Side)),
+		PositionSide:  strings.ToUpper(string(order.PositionSide)),
+		ReduceOnly:    order.ReduceOnly,
+		StopPrice:     binanceDecimal(order.StopPrice),
+		WorkingType:   "CONTRACT_PRICE",
+		Time:          order.CreateTS,
+		UpdateTime:    order.UpdateTS,
+	}
+
+	if order.Type == entities.OrderTypeTrailingStop {
+		result.ActivatePrice = binanceDecimal(order.ActivationPrice)
+		result.PriceRate = binanceDecimal(order.CallbackRate)
+	}
+
+	return result
+}
+
+// binanceFindOrder loads the order by the order id or by the client order id and checks its symbol,
+// an order placed without a client order id is found by its uid
+func (r *Routes) binanceFindOrder(c *gin.Context, exchange entities.Exchange, accountUID entities.AccountUID, req BinanceOrderRequest) (*entities.Order, error) {
+	symbol, err := r.binanceSymbol(exchange, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var order *entities.Order
+
+	switch {
+	case req.OrderID > 0:
+		order, err = r.usecase.GetOrderByID(c.Request.Context(), exchange, accountUID, req.OrderID)
+	case req.OrigClientOrderID != "":
+		order, err = r.usecase.GetOrderByClientID(c.Request.Context(), exchange, accountUID, req.OrigClientOrderID)
+		if errors.Is(err, apperror.ErrOrderNotFound) {
+			order, err = r.usecase.GetOrder(c.Request.Context(), exchange, accountUID, req.OrigClientOrderID)
+		}
+	default:
+		err = errBinanceOrderIDRequired
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Symbol != symbol {
+		return nil, apperror.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+func (r *Routes) getBinancePingHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (r *Routes) getBinanceTimeHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"serverTime": entities.TS(),
+	})
+}
+
+func (r *Routes) getBinanceExchangeInfoHandler(exchange entities.Exchange) gin.HandlerFunc {
+	orderTypes := make([]string, 0, len(binanceOrderTypes))
+	for name, orderType := range binanceOrderTypes {
+		order := entities.Order{Exchange: exchange, Type: orderType}
+		if name == binanceOrderType(&order) || (exchange == entities.ExchangeSpot && name == "LIMIT_MAKER") {
+			orderTypes = append(orderTypes, name)
+		}
+	}
+	sort.Strings(orderTypes)
+
+	return func(c *gin.Context) {
+		markets, err := r.markets.GetMarkets(exchange.Name())
+		if err != nil {
+			binanceError(c, exchange, err)
+			return
+		}
+
+		result := BinanceExchangeInfo{
+			Timezone:   "UTC",
+			ServerTime: entities.TS(),
+			Symbols:    make([]BinanceSymbol, 0, len(markets)),
+		}
+
+		for _, market := range markets {
+			status := "TRADING"
+			if !market.Active {
+				status = "BREAK"
+			}
+
+			result.Symbols = append(result.Symbols, BinanceSymbol{
+				Symbol:            market.ID,
+				Status:            status,
+				BaseAsset:         market.Base,
+				QuoteAsset:        market.Quote,
+				PricePrecision:    market.Precision.Price,
+				QuantityPrecision: market.Precision.Amount,
+				OrderTypes:        orderTypes,
+				Filters: []BinanceSymbolFilter{
+					{
+						FilterType: "PRICE_FILTER",
+						MinPrice:   binanceDecimal(market.Limits.Price.Min),
+						MaxPrice:   binanceDecimal(market.Limits.Price.Max),
+						TickSize:   binanceDecimal(math.Pow10(-int(market.Precision.Price))),
+					},
+					{
+						FilterType: "LOT_SIZE",
+						MinQty:     binanceDecimal(market.Limits.Amount.Min),
+						MaxQty:     binanceDecimal(market.Limits.Amount.Max),
+						StepSize:   binanceDecimal(math.Pow10(-int(market.Precision.Amount))),
+					},
+					{
+						FilterType:  "MIN_NOTIONAL",
+						MinNotional: binanceDecimal(market.Limits.Cost.Min),
+					},
+				},
+			})
+		}
+
+		sort.Slice(result.Symbols, func(i, j int) bool {
+			return result.Symbols[i].Symbol < result.Symbols[j].Symbol
+		})
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func (r *Routes) getBinanceTickerPriceHandler(exchange entities.Exchange) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tickers, err := r.tickers.GetTickers(exchange.Name())
+		if err != nil {
+			binanceError(c, exchange, err)
+			return
+		}
+
+		if id := c.Query("symbol"); id != "" {
+			symbol, err := r.binanceSymbol(exchange, id)
+			if err != nil {
+				binanceError(c, exchange, err)
+				return
+			}
+
+			ticker, ok := tickers[symbol.String()]
+			if !ok {
+				binanceError(c, exchange, apperror.ErrSymbolIsNotValid)
+				return
+			}
+
+			c.JSON(http.StatusOK, BinanceTickerPrice{
+				Symbol: id,
+				Price:  binanceDecimal(ticker.Last),
+				Time:   ticker.Timestamp,
+			})
+			return
+		}
+
+		result := make([]BinanceTickerPrice, 0, len(tickers))
+		for symbol, ticker := range tickers {
+			result = append(result, BinanceTickerPrice{
+				Symbol: r.binanceSymbolID(exchange, entities.Symbol(symbol)),
+				Price:  binanceDecimal(ticker.Last),
+				Time:   ticker.Timestamp,
+			})
+		}
+
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].Symbol < result[j].Symbol
+		})
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func (r *Routes) postBinanceOrderHandler(exchange entities.Exchange) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BinanceOrderCreateRequest
+		if err := c.ShouldBind(&req); err != nil {
+			binanceAbort(c, binanceCodeIllegalParameter, err.Error())
+			return
+		}
+
+		accountUID, ok := binanceAccountUID(c)
+		if !ok {
+			return
+		}
+
+		symbol, err := r.binanceSymbol(exchange, req.Symbol)
+		if err != nil {
+			binanceError(c, exchange, err)
+			return
+		}
+
+		orderType, ok := binanceOrderTypes[req.Type]
+		if !ok {
+			binanceError(c, exchange, apperror.ErrOrderTypeNotValid)
+			return
+		}
+
+		// Binance rejects a client order id already used by an open order
+		if req.ClientOrderID != "" {
+			if !binanceClientOrderIDPattern.MatchString(req.ClientOrderID) {
+				binanceError(c, exchange, errBinanceClientOrderID)
+				return
+			}
+
+			existing, err := r.usecase.GetOrderByClientID(c.Request.Context(), exchange, accountUID, req.ClientOrderID)
+			if err == nil && !existing.Status.IsFinal() {
+				binanceError(c, exchange, errBinanceDuplicateOrder)
+				return
+			}
+		}
+
+		order := entities.NewOrder(accountUID)
+		order.Exchange = exchange
+		order.Symbol = symbol
+		order.Type = orderType
+		order.Side = entities.OrderSide(strings.ToLower(req.Side))
+		order.Amount = req.Quantity
+		order.Price = req.Price
+		order.StopPrice = req.StopPrice
+		order.CallbackRate = req.CallbackRate
+		order.ActivationPrice = req.ActivationPrice
+		order.ReduceOnly = req.ReduceOnly
+		order.TimeInForce = entities.TimeInForce(req.TimeInForce)
+		order.ClientOrderID = req.ClientOrderID
+
+		if req.Type == "LIMIT_MAKER" {
+			order.TimeInForce = entities.TimeInForceGTX
+		}
+
+		if exchange == entities.ExchangeFutures {
+			order.PositionSide = entities.PositionSideBoth
+			if req.PositionSide != "" {
+				order.PositionSide = entities.PositionSide(strings.ToLower(req.PositionSide))
+			}
+		}
+
+		if err := r.usecase.NewOrder(c.Request.Context(), order); err != nil {
+			// the client order id is taken by an open order placed after the check above
+			if errors.Is(err, apperror.ErrDuplicateOrder) {
+				err = errBinanceDuplicateOrder
+			}
+			binanceError(c, exchange, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, r.binanceOrder(order))
+	}
+}
+
+func (r *Routes) getBinanceOrderHandler(exchange entities.Exchange) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BinanceOrderRequest
+		if err := c.ShouldBind(&req); err != nil {
+			binanceAbort(c, binanceCodeIllegalParameter, err.Error())
+			return
+		}
+
+		accountUID, ok := binanceAccountUID(c)
+		if !ok {
+			return
+		}
+
+		order, err := r.binanceFindOrder(c, exchange, accountUID, req)
+		if err != nil {
+			binanceError(c, exchange, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, r.binanceOrder(order))
+	}
+}
+
+func (r *Routes) deleteBinanceOrderHandler(exchange entities.Exchange) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BinanceOrderRequest
+		if err := c.ShouldBind(&req); err != nil {
+			binanceAbort(c, binanceCodeIllegalParameter, err.Error())
+			return
+		}
+
+		accountUID, ok := binanceAccountUID(c)
+		if !ok {
+			return
+		}
+
+		order, err := r.binanceFindOrder(c, exchange, accountUID, req)
+		if err == nil {
+			order, err = r.usecase.CancelOrder(c.Request.Context(), exchange, accountUID, order.OrderUID)
+		}
+
+		if err != nil {
+			// Binance rejects the cancel of an unknown or a closed order with its own code
+			if errors.Is(err, apperror.ErrOrderNotFound) {
+				binanceAbort(c, binanceCodeCancelRejected, "Unknown order sent.")
+				return
+			}
+			binanceError(c, exchange, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, r.binanceOrder(order))
+	}
+}
+
+func (r *Routes) getBinanceOpenOrdersHandler(exchange entities.Exchange) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountUID, ok := binanceAccountUID(c)
+		if !ok {
+			return
+		}
+
+		var symbol entities.Symbol
+		if id := c.Query("symbol"); id != "" {
+			var err error
+			if symbol, err = r.binanceSymbol(exchange, id); err != nil {
+				binanceError(c, exchange, err)
+				return
+			}
+		}
+
+		statuses := []entities.OrderStatus{entities.OrderStatusNew, entities.OrderStatusPending, entities.OrderStatusPartiallyFilled}
+		orders, err := r.usecase.OrdersList(c.Request.Context(), exchange, accountUID, statuses, binanceOpenOrdersLimit)
+		if err != nil {
+			binanceError(c, exchange, err)
+			return
+		}
+
+		result := make([]any, 0, len(orders))
+		for _, order := range orders {
+			if symbol == "" || order.Symbol == symbol {
+				result = append(result, r.binanceOrder(order))
+			}
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func (r *Routes) getBinanceSpotAccountHandler(c *gin.Context) {
+	accountUID, ok := binanceAccountUID(c)
+	if !ok {
+		return
+	}
+
+	balances, err := r.usecase.GetBalances(c.Request.Context(), entities.ExchangeSpot, accountUID)
+	if err != nil {
+		binanceError(c, entities.ExchangeSpot, err)
+		return
+	}
+
+	result := BinanceSpotAccount{
+		CanTrade:    true,
+		CanWithdraw: true,
+		CanDeposit:  true,
+		UpdateTime:  entities.TS(),
+		AccountType: "SPOT",
+		Balances:    make([]BinanceSpotBalance, 0, len(balances)),
+		Permissions: []string{"SPOT"},
+	}
+
+	for coin, balance := range balances {
+		result.Balances = append(result.Balances, BinanceSpotBalance{
+			Asset:  string(coin),
+			Free:   binanceDecimal(balance.Total - balance.Hold),
+			Locked: binanceDecimal(balance.Hold),
+		})
+	}
+
+	sort.Slice(result.Balances, func(i, j int) bool {
+		return result.Balances[i].Asset < result.Balances[j].Asset
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+// binanceMarginCoins returns the quote coins of the futures markets, the account totals are summed over them
+func (r *Routes) binanceMarginCoins() map[entities.Coin]bool {
+	result := make(map[entities.Coin]bool)
+
+	if markets, err := r.markets.GetMarkets(entities.ExchangeFutures.Name()); err == nil {
+		for _, market := range markets {
+			result[entities.Coin(market.Quote)] = true
+		}
+	}
+
+	return result
+}
+
+func sortedCoins(balances entities.Balances) []entities.Coin {
+	coins := make([]entities.Coin, 0, len(balances))
+	for coin := range balances {
+		coins = append(coins, coin)
+	}
+
+	sort.Slice(coins, func(i, j int) bool {
+		return coins[i] < coins[j]
+	})
+
+	return coins
+}
+
+func (r *Routes) getBinanceFuturesAccountHandler(c *gin.Context) {
+	accountUID, ok := binanceAccountUID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	balances, err := r.usecase.GetBalances(ctx, entities.ExchangeFutures, accountUID)
+	if err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	positions, err := r.usecase.PositionsList(ctx, entities.ExchangeFutures, accountUID)
+	if err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	ts := entities.TS()
+	marginCoins := r.binanceMarginCoins()
+
+	var total entities.Balance
+
+	assets := make([]BinanceFuturesAsset, 0, len(balances))
+	for _, coin := range sortedCoins(balances) {
+		balance := balances[coin]
+		if marginCoins[coin] {
+			total.InitialMargin += balance.InitialMargin
+			total.MaintMargin += balance.MaintMargin
+			total.WalletBalance += balance.WalletBalance
+			total.UnrealisedPnl += balance.UnrealisedPnl
+			total.MarginBalance += balance.MarginBalance
+			total.AvailableBalance += balance.AvailableBalance
+		}
+
+		assets = append(assets, BinanceFuturesAsset{
+			Asset:              string(coin),
+			WalletBalance:      binanceDecimal(balance.WalletBalance),
+			UnrealizedProfit:   binanceDecimal(balance.UnrealisedPnl),
+			MarginBalance:      binanceDecimal(balance.MarginBalance),
+			MaintMargin:        binanceDecimal(balance.MaintMargin),
+			InitialMargin:      binanceDecimal(balance.InitialMargin),
+			CrossWalletBalance: binanceDecimal(balance.WalletBalance),
+			AvailableBalance:   binanceDecimal(balance.AvailableBalance),
+			MaxWithdrawAmount:  binanceDecimal(balance.AvailableBalance),
+			MarginAvailable:    true,
+			UpdateTime:         ts,
+		})
+	}
+
+	result := BinanceFuturesAccount{
+		CanTrade:              true,
+		CanDeposit:            true,
+		CanWithdraw:           true,
+		UpdateTime:            ts,
+		TotalInitialMargin:    binanceDecimal(total.InitialMargin),
+		TotalMaintMargin:      binanceDecimal(total.MaintMargin),
+		TotalWalletBalance:    binanceDecimal(total.WalletBalance),
+		TotalUnrealizedProfit: binanceDecimal(total.UnrealisedPnl),
+		TotalMarginBalance:    binanceDecimal(total.MarginBalance),
+		AvailableBalance:      binanceDecimal(total.AvailableBalance),
+		MaxWithdrawAmount:     binanceDecimal(total.AvailableBalance),
+		Assets:                assets,
+		Positions:             make([]BinanceFuturesAccountPosition, 0, len(positions)),
+	}
+
+	for _, position := range positions {
+		result.Positions = append(result.Positions, BinanceFuturesAccountPosition{
+			Symbol:           r.binanceSymbolID(entities.ExchangeFutures, position.Symbol),
+			InitialMargin:    binanceDecimal(position.Margin),
+			MaintMargin:      binanceDecimal(position.MaintMargin),
+			UnrealizedProfit: binanceDecimal(position.UnrealisedPnl),
+			Leverage:         strconv.Itoa(int(position.Leverage)),
+			Isolated:         position.MarginType == entities.MarginTypeIsolated,
+			EntryPrice:       binanceDecimal(position.Price),
+			PositionSide:     strings.ToUpper(string(position.Side)),
+			PositionAmt:      binanceDecimal(binancePositionAmount(position)),
+			UpdateTime:       position.UpdateTS,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Routes) getBinanceFuturesBalanceHandler(c *gin.Context) {
+	accountUID, ok := binanceAccountUID(c)
+	if !ok {
+		return
+	}
+
+	balances, err := r.usecase.GetBalances(c.Request.Context(), entities.ExchangeFutures, accountUID)
+	if err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	ts := entities.TS()
+
+	result := make([]BinanceFuturesBalance, 0, len(balances))
+	for _, coin := range sortedCoins(balances) {
+		balance := balances[coin]
+		result = append(result, BinanceFuturesBalance{
+			AccountAlias:       string(accountUID),
+			Asset:              string(coin),
+			Balance:            binanceDecimal(balance.WalletBalance),
+			CrossWalletBalance: binanceDecimal(balance.WalletBalance),
+			CrossUnPnl:         binanceDecimal(balance.UnrealisedPnl),
+			AvailableBalance:   binanceDecimal(balance.AvailableBalance),
+			MaxWithdrawAmount:  binanceDecimal(balance.AvailableBalance),
+			MarginAvailable:    true,
+			UpdateTime:         ts,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// binancePositionAmount is signed like on Binance, a short position of the hedge mode is negative
+func binancePositionAmount(position *entities.Position) float64 {
+	if position.Mode == entities.PositionModeHedge && position.Side == entities.PositionSideShort {
+		return -position.Amount
+	}
+
+	return position.Amount
+}
+
+func (r *Routes) getBinancePositionRiskHandler(c *gin.Context) {
+	accountUID, ok := binanceAccountUID(c)
+	if !ok {
+		return
+	}
+
+	var symbol entities.Symbol
+	if id := c.Query("symbol"); id != "" {
+		var err error
+		if symbol, err = r.binanceSymbol(entities.ExchangeFutures, id); err != nil {
+			binanceError(c, entities.ExchangeFutures, err)
+			return
+		}
+	}
+
+	positions, err := r.usecase.PositionsList(c.Request.Context(), entities.ExchangeFutures, accountUID)
+	if err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	result := make([]BinancePositionRisk, 0, len(positions))
+	for _, position := range positions {
+		if symbol != "" && position.Symbol != symbol {
+			continue
+		}
+
+		amount := binancePositionAmount(position)
+
+		marginType := "cross"
+		isolatedMargin := 0.0
+		if position.MarginType == entities.MarginTypeIsolated {
+			marginType = "isolated"
+			isolatedMargin = position.MarginBalance
+		}
+
+		result = append(result, BinancePositionRisk{
+			Symbol:           r.binanceSymbolID(entities.ExchangeFutures, position.Symbol),
+			PositionAmt:      binanceDecimal(amount),
+			EntryPrice:       binanceDecimal(position.Price),
+			MarkPrice:        binanceDecimal(position.MarkPrice),
+			UnRealizedProfit: binanceDecimal(position.UnrealisedPnl),
+			LiquidationPrice: binanceDecimal(position.LiquidationPrice),
+			Leverage:         strconv.Itoa(int(position.Leverage)),
+			MarginType:       marginType,
+			IsolatedMargin:   binanceDecimal(isolatedMargin),
+			IsAutoAddMargin:  "false",
+			PositionSide:     strings.ToUpper(string(position.Side)),
+			Notional:         binanceDecimal(amount * position.MarkPrice),
+			IsolatedWallet:   binanceDecimal(position.Margin),
+			UpdateTime:       position.UpdateTS,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Routes) postBinanceLeverageHandler(c *gin.Context) {
+	var req BinanceLeverageRequest
+	if err := c.ShouldBind(&req); err != nil {
+		binanceAbort(c, binanceCodeIllegalParameter, err.Error())
+		return
+	}
+
+	accountUID, ok := binanceAccountUID(c)
+	if !ok {
+		return
+	}
+
+	symbol, err := r.binanceSymbol(entities.ExchangeFutures, req.Symbol)
+	if err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	if err := r.usecase.SetPositionLeverage(c.Request.Context(), entities.ExchangeFutures, accountUID, symbol, entities.PositionLeverage(req.Leverage)); err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":   req.Symbol,
+		"leverage": req.Leverage,
+	})
+}
+
+func (r *Routes) postBinanceMarginTypeHandler(c *gin.Context) {
+	var req BinanceMarginTypeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		binanceAbort(c, binanceCodeIllegalParameter, err.Error())
+		return
+	}
+
+	accountUID, ok := binanceAccountUID(c)
+	if !ok {
+		return
+	}
+
+	symbol, err := r.binanceSymbol(entities.ExchangeFutures, req.Symbol)
+	if err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	var marginType entities.MarginType
+	switch req.MarginType {
+	case "ISOLATED":
+		marginType = entities.MarginTypeIsolated
+	case "CROSSED":
+		marginType = entities.MarginTypeCross
+	default:
+		binanceAbort(c, binanceCodeIllegalParameter, "Invalid marginType.")
+		return
+	}
+
+	if err := r.usecase.SetPositionMarginType(c.Request.Context(), entities.ExchangeFutures, accountUID, symbol, marginType); err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BinanceError{
+		Code: http.StatusOK,
+		Msg:  "success",
+	})
+}
+
+func (r *Routes) getBinancePositionSideHandler(c *gin.Context) {
+	accountUID, ok := binanceAccountUID(c)
+	if !ok {
+		return
+	}
+
+	account, err := r.usecase.GetAccountByUID(c.Request.Context(), accountUID)
+	if err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dualSidePosition": account.PositionMode == entities.PositionModeHedge,
+	})
+}
+
+func (r *Routes) postBinancePositionSideHandler(c *gin.Context) {
+	var req BinancePositionSideRequest
+	if err := c.ShouldBind(&req); err != nil {
+		binanceAbort(c, binanceCodeIllegalParameter, err.Error())
+		return
+	}
+
+	accountUID, ok := binanceAccountUID(c)
+	if !ok {
+		return
+	}
+
+	mode := entities.PositionModeOneway
+	if req.DualSidePosition {
+		mode = entities.PositionModeHedge
+	}
+
+	if err := r.usecase.SetAccountPositionMode(c.Request.Context(), entities.ExchangeFutures, accountUID, mode); err != nil {
+		binanceError(c, entities.ExchangeFutures, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BinanceError{
+		Code: http.StatusOK,
+		Msg:  "success",
+	})
+}
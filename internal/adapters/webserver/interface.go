@@ -5,6 +5,7 @@ import (
 
 	"DemoExchange/internal/app/entities"
 	"DemoExchange/internal/app/markets"
+	"DemoExchange/internal/app/marketstream"
 	"DemoExchange/internal/app/orderbook"
 	"DemoExchange/internal/app/tickers"
 )
@@ -21,25 +22,60 @@ type Orderbook interface {
 	GetOrderbook(ctx context.Context, exchange, symbol, limit string) (*orderbook.Orderbook, error)
 }
 
+type MarketStream interface {
+	NewClient() *marketstream.Client
+	Subscribe(c *marketstream.Client, topic marketstream.Topic) error
+	Unsubscribe(c *marketstream.Client, topic marketstream.Topic)
+	Close(c *marketstream.Client)
+}
+
 type Usecase interface {
+	GetAccountByUID(ctx context.Context, accountUID entities.AccountUID) (*entities.Account, error)
 	SetAccountPositionMode(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, positionMode entities.PositionMode) error
+	SetAccountFeeTier(ctx context.Context, service, userID, tier string) (*entities.Account, error)
+	ResetAccount(ctx context.Context, accountUID entities.AccountUID) error
+
+	InsuranceFundBalance(ctx context.Context, exchange entities.Exchange, coin entities.Coin) (float64, error)
+	InsuranceFundDeposit(ctx context.Context, exchange entities.Exchange, coin entities.Coin, amount float64) (float64, error)
 
-	CreateToken(ctx context.Context, service, userID string) (entities.Token, error)
+	CreateToken(ctx context.Context, service, userID string, settings entities.KeySettings) (*entities.Key, error)
 	DisableToken(ctx context.Context, token entities.Token) error
-	GetAccountUID(ctx context.Context, token entities.Token) (entities.AccountUID, error)
+	UpdateToken(ctx context.Context, token entities.Token, settings entities.KeySettings) (*entities.Key, error)
+	RotateTokenSecret(ctx context.Context, token entities.Token) (*entities.Key, error)
+	KeysList(ctx context.Context, service, userID string) ([]entities.Key, error)
+	AuthenticateRequest(ctx context.Context, req *entities.SignedRequest) (*entities.Key, error)
 
 	GetBalances(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID) (entities.Balances, error)
 	Deposit(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin, amount float64) (float64, error)
 	Withdraw(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin, amount float64) error
+	Transfer(ctx context.Context, accountUID entities.AccountUID, coin entities.Coin, from, to entities.Exchange, amount float64) error
+
+	CreateSubAccount(ctx context.Context, masterUID entities.AccountUID, label string) (*entities.Account, *entities.Key, error)
+	CreateSubAccountToken(ctx context.Context, masterUID, accountUID entities.AccountUID) (*entities.Key, error)
+	SubAccountsList(ctx context.Context, masterUID entities.AccountUID) ([]*entities.Account, error)
+	SubAccountTransfer(ctx context.Context, masterUID, from, to entities.AccountUID, exchange entities.Exchange, coin entities.Coin, amount float64) error
+	SubAccountBalances(ctx context.Context, exchange entities.Exchange, masterUID entities.AccountUID) (*entities.AccountBalances, error)
+
+	CreateListenKey(ctx context.Context, accountUID entities.AccountUID) *entities.ListenKey
+	GetListenKey(ctx context.Context, listenKey string) (*entities.ListenKey, error)
+	KeepAliveListenKey(ctx context.Context, accountUID entities.AccountUID, listenKey string) (*entities.ListenKey, error)
+	DeleteListenKey(ctx context.Context, accountUID entities.AccountUID, listenKey string) error
+	SubscribeUserData(ctx context.Context, accountUID entities.AccountUID) (<-chan *entities.UserEvent, func())
+	UserDataSnapshot(ctx context.Context, accountUID entities.AccountUID) (*entities.UserSnapshot, error)
 
 	NewOrder(ctx context.Context, order *entities.Order) error
+	NewOCOOrder(ctx context.Context, limit, stop *entities.Order) error
 	GetOrder(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, orderUID string) (*entities.Order, error)
+	GetOrderByID(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, orderID int64) (*entities.Order, error)
+	GetOrderByClientID(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, clientOrderID string) (*entities.Order, error)
 	CancelOrder(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, orderUID string) (*entities.Order, error)
 	OrdersList(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, statuses []entities.OrderStatus, limit int) ([]*entities.Order, error)
+	TradesList(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, filter entities.TradeFilter) ([]*entities.Trade, error)
 
 	PositionsList(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID) ([]*entities.Position, error)
 	SetPositionMarginType(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol entities.Symbol, marginType entities.MarginType) error
 	SetPositionLeverage(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol entities.Symbol, leverage entities.PositionLeverage) error
+	SetPositionTPSL(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol entities.Symbol, side entities.PositionSide, takeProfit, stopLoss float64) error
 
 	TransactionsList(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, filter entities.TransactionFilter) ([]*entities.Transaction, error)
 }
@@ -0,0 +1,131 @@
+package webserver
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"DemoExchange/internal/app/marketstream"
+)
+
+// getMarketWSHandler streams the tickers, mark prices and orderbooks of the symbols the client subscribes to
+func (r *Routes) getMarketWSHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		r.log.Errorf("getMarketWSHandler:Upgrade error: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	client := r.stream.NewClient()
+	defer r.stream.Close(client)
+
+	done := make(chan struct{})
+	replies := make(chan any, 10)
+
+	// the context is cancelled once the handler returns, so the reader never blocks on a stopped writer
+	reply := func(v any) bool {
+		select {
+		case replies <- v:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(done)
+
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+			if strings.EqualFold(strings.TrimSpace(string(message)), "ping") {
+				if !reply("pong") {
+					return
+				}
+				continue
+			}
+
+			var req MarketStreamRequest
+			if err := json.Unmarshal(message, &req); err != nil {
+				if !reply(gin.H{"success": false, "error": err.Error()}) {
+					return
+				}
+				continue
+			}
+
+			if !reply(r.handleMarketStreamRequest(client, req)) {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+
+		case message := <-client.Messages():
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(message); err != nil {
+				return
+			}
+
+		case v := <-replies:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if text, ok := v.(string); ok {
+				err = conn.WriteMessage(websocket.TextMessage, []byte(text))
+			} else {
+				err = conn.WriteJSON(v)
+			}
+			if err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (r *Routes) handleMarketStreamRequest(client *marketstream.Client, req MarketStreamRequest) gin.H {
+	switch req.Method {
+	case "subscribe":
+		for _, topic := range req.Params {
+			if err := r.stream.Subscribe(client, topic); err != nil {
+				return gin.H{"id": req.ID, "success": false, "error": err.Error()}
+			}
+		}
+	case "unsubscribe":
+		for _, topic := range req.Params {
+			r.stream.Unsubscribe(client, topic)
+		}
+	default:
+		return gin.H{"id": req.ID, "success": false, "error": "Method is not valid"}
+	}
+
+	return gin.H{"id": req.ID, "success": true}
+}
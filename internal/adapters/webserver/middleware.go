@@ -1,11 +1,18 @@
 package webserver
 
 import (
+	"DemoExchange/internal/app/apperror"
 	"DemoExchange/internal/app/entities"
+	"bytes"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 func authSecretMiddleware(secrets []string) gin.HandlerFunc {
@@ -28,20 +35,147 @@ func authSecretMiddleware(secrets []string) gin.HandlerFunc {
 
 func (r *Routes) authTokenMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx := c.Request.Context()
-		token := c.GetHeader("token")
-		accountUID, err := r.usecase.GetAccountUID(ctx, entities.Token(token))
+		key, err := r.authenticate(c, c.GetHeader("token"))
 		if err != nil {
-			r.log.Errorf("GetAccountUID error: %v [headers: %v]", err, c.Request.Header)
 			c.AbortWithStatusJSON(http.StatusOK, gin.H{
-				"error": "Invalid API-key",
+				"error": err.Error(),
 				"time":  time.Now().Format("2006-01-02 15:04:05"),
 			})
 			return
 		}
 
-		c.Set("accountUID", accountUID)
+		c.Set("accountUID", key.AccountUID)
+		c.Set("apiKey", key)
 
 		c.Next()
 	}
 }
+
+// scopeMiddleware lets through the requests made with a key allowed the scope
+func (r *Routes) scopeMiddleware(scope entities.KeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !r.checkScope(c, scope) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// checkScope answers the request with an error unless its key is allowed the scope
+func (r *Routes) checkScope(c *gin.Context, scope entities.KeyScope) bool {
+	if keyHasScope(c, scope) {
+		return true
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, gin.H{
+		"success": false,
+		"error":   apperror.ErrPermissionDenied.Error(),
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+
+	return false
+}
+
+func keyHasScope(c *gin.Context, scope entities.KeyScope) bool {
+	key, exists := c.Get("apiKey")
+	if !exists {
+		return false
+	}
+
+	return key.(*entities.Key).HasScope(scope)
+}
+
+// authenticate checks the signature of the request made with the token and returns its key
+func (r *Routes) authenticate(c *gin.Context, token string) (*entities.Key, error) {
+	req, err := signedRequest(c, token)
+	if err != nil {
+		r.log.Errorf("signedRequest [path: %s] error: %v", c.Request.URL.Path, err)
+		return nil, err
+	}
+
+	key, err := r.usecase.AuthenticateRequest(c.Request.Context(), req)
+	if err != nil {
+		r.log.Errorf("AuthenticateRequest [path: %s] [ip: %s] error: %v", c.Request.URL.Path, req.IP, err)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// signedRequest collects the signed payload as Binance does: the query string followed by the form body,
+// both without the signature parameter. A json body is signed as is, its timestamp and signature go to the query.
+func signedRequest(c *gin.Context, token string) (*entities.SignedRequest, error) {
+	var body []byte
+
+	if c.Request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			return nil, apperror.ErrRequestError
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	query, signature := cutSignature(c.Request.URL.RawQuery)
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, apperror.ErrRequestError
+	}
+
+	payload := string(body)
+	if c.ContentType() == binding.MIMEPOSTForm {
+		var bodySignature string
+		payload, bodySignature = cutSignature(payload)
+		if signature == "" {
+			signature = bodySignature
+		}
+
+		form, err := url.ParseQuery(payload)
+		if err != nil {
+			return nil, apperror.ErrRequestError
+		}
+		for k, v := range form {
+			params[k] = append(params[k], v...)
+		}
+	}
+
+	req := &entities.SignedRequest{
+		Token:     entities.Token(token),
+		IP:        c.ClientIP(),
+		Payload:   query + payload,
+		Signature: signature,
+	}
+
+	// an unsigned key sends no timestamp, the signed requests are checked for it on authentication
+	if timestamp := params.Get("timestamp"); timestamp != "" {
+		if req.Timestamp, err = strconv.ParseInt(timestamp, 10, 64); err != nil {
+			return nil, apperror.ErrTimestampIsNotValid
+		}
+	}
+
+	if recvWindow := params.Get("recvWindow"); recvWindow != "" {
+		if req.RecvWindow, err = strconv.ParseInt(recvWindow, 10, 64); err != nil {
+			return nil, apperror.ErrRecvWindowIsNotValid
+		}
+	}
+
+	return req, nil
+}
+
+// cutSignature removes the signature parameter from the encoded parameters and returns its value
+func cutSignature(params string) (string, string) {
+	var signature string
+
+	parts := strings.Split(params, "&")
+	result := parts[:0]
+	for _, part := range parts {
+		if value, ok := strings.CutPrefix(part, "signature="); ok {
+			signature = value
+			continue
+		}
+		result = append(result, part)
+	}
+
+	return strings.Join(result, "&"), signature
+}
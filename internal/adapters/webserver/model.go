@@ -1,5 +1,10 @@
 package webserver
 
+import (
+	"DemoExchange/internal/app/entities"
+	"DemoExchange/internal/app/marketstream"
+)
+
 type Responce struct {
 	Success bool        `json:"success"`
 	Return  interface{} `json:"return,omitempty"`
@@ -11,6 +16,7 @@ type CreateTokenRequest struct {
 	// Exchange string `json:"exchange"`
 	Service string `json:"service"`
 	UserID  string `json:"user_id"`
+	KeySettingsRequest
 }
 
 type DisableTokenRequest struct {
@@ -18,6 +24,46 @@ type DisableTokenRequest struct {
 	Token string `json:"token"`
 }
 
+type UpdateTokenRequest struct {
+	Token string `json:"token"`
+	KeySettingsRequest
+}
+
+type RotateTokenSecretRequest struct {
+	Token string `json:"token"`
+}
+
+// KeySettingsRequest holds the key settings, an omitted field is left unchanged and a new key gets all scopes
+type KeySettingsRequest struct {
+	Label       *string  `json:"label"`
+	Scopes      []string `json:"scopes"`
+	ExpireTS    *int64   `json:"expire_ts"`
+	IPWhitelist []string `json:"ip_whitelist"`
+}
+
+func (r KeySettingsRequest) Settings() entities.KeySettings {
+	settings := entities.KeySettings{
+		Label:       r.Label,
+		ExpireTS:    r.ExpireTS,
+		IPWhitelist: r.IPWhitelist,
+	}
+
+	if r.Scopes != nil {
+		settings.Scopes = make([]entities.KeyScope, 0, len(r.Scopes))
+		for _, scope := range r.Scopes {
+			settings.Scopes = append(settings.Scopes, entities.KeyScope(scope))
+		}
+	}
+
+	return settings
+}
+
+type AccountTierRequest struct {
+	Service string `json:"service"`
+	UserID  string `json:"user_id"`
+	Tier    string `json:"tier"`
+}
+
 type DepositRequest struct {
 	Exchange string  `json:"exchange"`
 	Coin     string  `json:"coin"`
@@ -30,6 +76,41 @@ type WithdrawRequest struct {
 	Amount   float64 `json:"amount"`
 }
 
+type TransferRequest struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Coin   string  `json:"coin"`
+	Amount float64 `json:"amount"`
+}
+
+type SubAccountCreateRequest struct {
+	Label string `json:"label"`
+}
+
+type SubAccountTokenRequest struct {
+	AccountUID string `json:"account_uid"`
+}
+
+// SubAccountTransferRequest moves funds inside the account family, an empty account uid is the master
+type SubAccountTransferRequest struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Exchange string  `json:"exchange"`
+	Coin     string  `json:"coin"`
+	Amount   float64 `json:"amount"`
+}
+
+// MarketStreamRequest is a message of the market data stream client, method is subscribe or unsubscribe
+type MarketStreamRequest struct {
+	ID     int64                `json:"id"`
+	Method string               `json:"method"`
+	Params []marketstream.Topic `json:"params"`
+}
+
+type ListenKeyRequest struct {
+	ListenKey string `json:"listen_key"`
+}
+
 type OrderCreateRequest struct {
 	Exchange     string  `json:"exchange"`
 	Symbol       string  `json:"symbol"`
@@ -38,7 +119,22 @@ type OrderCreateRequest struct {
 	Side         string  `json:"side"`
 	Amount       float64 `json:"amount"`
 	Price        float64 `json:"price"`
+	StopPrice    float64 `json:"stop_price"`
 	ReduceOnly   bool    `json:"reduce_only"`
+	TimeInForce  string  `json:"time_in_force"`
+
+	CallbackRate    float64 `json:"callback_rate"`
+	ActivationPrice float64 `json:"activation_price"`
+}
+
+type OrderOCORequest struct {
+	Exchange       string  `json:"exchange"`
+	Symbol         string  `json:"symbol"`
+	Side           string  `json:"side"`
+	Amount         float64 `json:"amount"`
+	Price          float64 `json:"price"`
+	StopPrice      float64 `json:"stop_price"`
+	StopLimitPrice float64 `json:"stop_limit_price"`
 }
 
 type OrderRequest struct {
@@ -62,3 +158,226 @@ type PositionLeverageRequest struct {
 	Symbol   string `json:"symbol"`
 	Leverage int32  `json:"leverage"`
 }
+
+type PositionTPSLRequest struct {
+	Exchange     string  `json:"exchange"`
+	Symbol       string  `json:"symbol"`
+	PositionSide string  `json:"position_side"`
+	TakeProfit   float64 `json:"take_profit"`
+	StopLoss     float64 `json:"stop_loss"`
+}
+
+// BinanceOrderCreateRequest holds the parameters of the Binance new order endpoints
+type BinanceOrderCreateRequest struct {
+	Symbol          string  `form:"symbol"`
+	Side            string  `form:"side"`
+	PositionSide    string  `form:"positionSide"`
+	Type            string  `form:"type"`
+	TimeInForce     string  `form:"timeInForce"`
+	Quantity        float64 `form:"quantity"`
+	Price           float64 `form:"price"`
+	StopPrice       float64 `form:"stopPrice"`
+	ReduceOnly      bool    `form:"reduceOnly"`
+	CallbackRate    float64 `form:"callbackRate"`
+	ActivationPrice float64 `form:"activationPrice"`
+	ClientOrderID   string  `form:"newClientOrderId"`
+}
+
+// BinanceOrderRequest selects an order by its numeric id or by the client order id,
+// the client order id of an order placed without one is the order uid
+type BinanceOrderRequest struct {
+	Symbol            string `form:"symbol"`
+	OrderID           int64  `form:"orderId"`
+	OrigClientOrderID string `form:"origClientOrderId"`
+}
+
+type BinanceSymbolRequest struct {
+	Symbol string `form:"symbol"`
+}
+
+type BinanceLeverageRequest struct {
+	Symbol   string `form:"symbol"`
+	Leverage int32  `form:"leverage"`
+}
+
+type BinanceMarginTypeRequest struct {
+	Symbol     string `form:"symbol"`
+	MarginType string `form:"marginType"`
+}
+
+type BinancePositionSideRequest struct {
+	DualSidePosition bool `form:"dualSidePosition"`
+}
+
+type BinanceError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+type BinanceSpotOrder struct {
+	Symbol              string `json:"symbol"`
+	OrderID             int64  `json:"orderId"`
+	OrderListID         int64  `json:"orderListId"`
+	ClientOrderID       string `json:"clientOrderId"`
+	TransactTime        int64  `json:"transactTime"`
+	Price               string `json:"price"`
+	OrigQty             string `json:"origQty"`
+	ExecutedQty         string `json:"executedQty"`
+	CummulativeQuoteQty string `json:"cummulativeQuoteQty"`
+	Status              string `json:"status"`
+	TimeInForce         string `json:"timeInForce"`
+	Type                string `json:"type"`
+	Side                string `json:"side"`
+	StopPrice           string `json:"stopPrice"`
+	Time                int64  `json:"time"`
+	UpdateTime          int64  `json:"updateTime"`
+	IsWorking           bool   `json:"isWorking"`
+}
+
+type BinanceFuturesOrder struct {
+	Symbol        string `json:"symbol"`
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Price         string `json:"price"`
+	AvgPrice      string `json:"avgPrice"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	CumQuote      string `json:"cumQuote"`
+	Status        string `json:"status"`
+	TimeInForce   string `json:"timeInForce"`
+	Type          string `json:"type"`
+	OrigType      string `json:"origType"`
+	Side          string `json:"side"`
+	PositionSide  string `json:"positionSide"`
+	ReduceOnly    bool   `json:"reduceOnly"`
+	ClosePosition bool   `json:"closePosition"`
+	StopPrice     string `json:"stopPrice"`
+	ActivatePrice string `json:"activatePrice,omitempty"`
+	PriceRate     string `json:"priceRate,omitempty"`
+	WorkingType   string `json:"workingType"`
+	PriceProtect  bool   `json:"priceProtect"`
+	Time          int64  `json:"time"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+type BinanceSpotBalance struct {
+	Asset  string `json:"asset"`
+	Free   string `json:"free"`
+	Locked string `json:"locked"`
+}
+
+type BinanceSpotAccount struct {
+	CanTrade    bool                 `json:"canTrade"`
+	CanWithdraw bool                 `json:"canWithdraw"`
+	CanDeposit  bool                 `json:"canDeposit"`
+	UpdateTime  int64                `json:"updateTime"`
+	AccountType string               `json:"accountType"`
+	Balances    []BinanceSpotBalance `json:"balances"`
+	Permissions []string             `json:"permissions"`
+}
+
+type BinanceFuturesAsset struct {
+	Asset              string `json:"asset"`
+	WalletBalance      string `json:"walletBalance"`
+	UnrealizedProfit   string `json:"unrealizedProfit"`
+	MarginBalance      string `json:"marginBalance"`
+	MaintMargin        string `json:"maintMargin"`
+	InitialMargin      string `json:"initialMargin"`
+	CrossWalletBalance string `json:"crossWalletBalance"`
+	AvailableBalance   string `json:"availableBalance"`
+	MaxWithdrawAmount  string `json:"maxWithdrawAmount"`
+	MarginAvailable    bool   `json:"marginAvailable"`
+	UpdateTime         int64  `json:"updateTime"`
+}
+
+type BinanceFuturesBalance struct {
+	AccountAlias       string `json:"accountAlias"`
+	Asset              string `json:"asset"`
+	Balance            string `json:"balance"`
+	CrossWalletBalance string `json:"crossWalletBalance"`
+	CrossUnPnl         string `json:"crossUnPnl"`
+	AvailableBalance   string `json:"availableBalance"`
+	MaxWithdrawAmount  string `json:"maxWithdrawAmount"`
+	MarginAvailable    bool   `json:"marginAvailable"`
+	UpdateTime         int64  `json:"updateTime"`
+}
+
+type BinanceFuturesAccountPosition struct {
+	Symbol           string `json:"symbol"`
+	InitialMargin    string `json:"initialMargin"`
+	MaintMargin      string `json:"maintMargin"`
+	UnrealizedProfit string `json:"unrealizedProfit"`
+	Leverage         string `json:"leverage"`
+	Isolated         bool   `json:"isolated"`
+	EntryPrice       string `json:"entryPrice"`
+	PositionSide     string `json:"positionSide"`
+	PositionAmt      string `json:"positionAmt"`
+	UpdateTime       int64  `json:"updateTime"`
+}
+
+type BinanceFuturesAccount struct {
+	CanTrade              bool                            `json:"canTrade"`
+	CanDeposit            bool                            `json:"canDeposit"`
+	CanWithdraw           bool                            `json:"canWithdraw"`
+	UpdateTime            int64                           `json:"updateTime"`
+	TotalInitialMargin    string                          `json:"totalInitialMargin"`
+	TotalMaintMargin      string                          `json:"totalMaintMargin"`
+	TotalWalletBalance    string                          `json:"totalWalletBalance"`
+	TotalUnrealizedProfit string                          `json:"totalUnrealizedProfit"`
+	TotalMarginBalance    string                          `json:"totalMarginBalance"`
+	AvailableBalance      string                          `json:"availableBalance"`
+	MaxWithdrawAmount     string                          `json:"maxWithdrawAmount"`
+	Assets                []BinanceFuturesAsset           `json:"assets"`
+	Positions             []BinanceFuturesAccountPosition `json:"positions"`
+}
+
+type BinancePositionRisk struct {
+	Symbol           string `json:"symbol"`
+	PositionAmt      string `json:"positionAmt"`
+	EntryPrice       string `json:"entryPrice"`
+	MarkPrice        string `json:"markPrice"`
+	UnRealizedProfit string `json:"unRealizedProfit"`
+	LiquidationPrice string `json:"liquidationPrice"`
+	Leverage         string `json:"leverage"`
+	MarginType       string `json:"marginType"`
+	IsolatedMargin   string `json:"isolatedMargin"`
+	IsAutoAddMargin  string `json:"isAutoAddMargin"`
+	PositionSide     string `json:"positionSide"`
+	Notional         string `json:"notional"`
+	IsolatedWallet   string `json:"isolatedWallet"`
+	UpdateTime       int64  `json:"updateTime"`
+}
+
+type BinanceSymbolFilter struct {
+	FilterType  string `json:"filterType"`
+	MinPrice    string `json:"minPrice,omitempty"`
+	MaxPrice    string `json:"maxPrice,omitempty"`
+	TickSize    string `json:"tickSize,omitempty"`
+	MinQty      string `json:"minQty,omitempty"`
+	MaxQty      string `json:"maxQty,omitempty"`
+	StepSize    string `json:"stepSize,omitempty"`
+	MinNotional string `json:"minNotional,omitempty"`
+}
+
+type BinanceSymbol struct {
+	Symbol            string                `json:"symbol"`
+	Status            string                `json:"status"`
+	BaseAsset         string                `json:"baseAsset"`
+	QuoteAsset        string                `json:"quoteAsset"`
+	PricePrecision    int64                 `json:"pricePrecision"`
+	QuantityPrecision int64                 `json:"quantityPrecision"`
+	OrderTypes        []string              `json:"orderTypes"`
+	Filters           []BinanceSymbolFilter `json:"filters"`
+}
+
+type BinanceExchangeInfo struct {
+	Timezone   string          `json:"timezone"`
+	ServerTime int64           `json:"serverTime"`
+	Symbols    []BinanceSymbol `json:"symbols"`
+}
+
+type BinanceTickerPrice struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+	Time   int64  `json:"time,omitempty"`
+}
@@ -27,8 +27,13 @@ func (r *Routes) Handler() http.Handler {
 	// g := gin.Default()
 	g := gin.New()
 
+	// the client address is checked against the key whitelists, it is taken from the forwarded headers of the trusted proxies only
+	if err := g.SetTrustedProxies(r.cfg.TrustedProxies); err != nil {
+		r.log.Errorf("SetTrustedProxies error: %v", err)
+	}
+
 	g.Use(cors.Default())
-	g.Use(gzip.Gzip(gzip.DefaultCompression))
+	g.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/v1/userdata/ws", "/v1/market/ws"})))
 	// g.Use(r.middlewareWhitelistIP())
 
 	g.GET("/stat", r.getStatHandler)
@@ -46,24 +51,60 @@ func (r *Routes) Handler() http.Handler {
 	market.GET("/tickers", r.getMarketTickersHandler)
 	market.GET("/orderbook", r.getMarketOrderbookHandler)
 	market.GET("/history/orders", r.getMarketHistoryOrdersHandler)
+	market.GET("/ws", r.getMarketWSHandler)
 
 	apikey := v1.Group("/apikey")
 	apikey.Use(authSecretMiddleware(r.cfg.AllowServiceTokens))
 	apikey.POST("/create", r.postAPIKeyCreateHandler)
 	apikey.POST("/disable", r.postAPIKeyDisableHandler)
+	apikey.GET("/list", r.getAPIKeyListHandler)
+	apikey.POST("/update", r.postAPIKeyUpdateHandler)
+	apikey.POST("/secret", r.postAPIKeySecretHandler)
+
+	account := v1.Group("/account")
+	account.Use(authSecretMiddleware(r.cfg.AllowServiceTokens))
+	account.POST("/tier", r.postAccountTierHandler)
+
+	// the reset is done by the account owner with its own token
+	v1.POST("/account/reset", r.authTokenMiddleware(), r.scopeMiddleware(entities.KeyScopeWallet), r.postAccountResetHandler)
+
+	insurance := v1.Group("/insurance")
+	insurance.Use(authSecretMiddleware(r.cfg.AllowServiceTokens))
+	insurance.GET("/balance", r.getInsuranceBalanceHandler)
+	insurance.POST("/deposit", r.postInsuranceDepositHandler)
 
 	wallet := v1.Group("/wallet")
 	wallet.Use(r.authTokenMiddleware())
 	wallet.GET("/balances", r.getWalletBalancesHandler)
-	wallet.POST("/deposit", r.postWalletDepositHandler)
-	wallet.POST("/withdraw", r.postWalletWithdrawHandler)
+	wallet.POST("/deposit", r.scopeMiddleware(entities.KeyScopeWallet), r.postWalletDepositHandler)
+	wallet.POST("/withdraw", r.scopeMiddleware(entities.KeyScopeWallet), r.postWalletWithdrawHandler)
+	wallet.POST("/transfer", r.scopeMiddleware(entities.KeyScopeWallet), r.postWalletTransferHandler)
+
+	subaccount := v1.Group("/subaccount")
+	subaccount.Use(r.authTokenMiddleware())
+	subaccount.POST("/create", r.scopeMiddleware(entities.KeyScopeWallet), r.postSubAccountCreateHandler)
+	subaccount.POST("/token", r.scopeMiddleware(entities.KeyScopeWallet), r.postSubAccountTokenHandler)
+	subaccount.GET("/list", r.getSubAccountListHandler)
+	subaccount.POST("/transfer", r.scopeMiddleware(entities.KeyScopeWallet), r.postSubAccountTransferHandler)
+	subaccount.GET("/balances", r.getSubAccountBalancesHandler)
+
+	listenKey := v1.Group("/userdata/listenkey")
+	listenKey.Use(r.authTokenMiddleware())
+	listenKey.POST("", r.postListenKeyHandler)
+	listenKey.PUT("", r.putListenKeyHandler)
+	listenKey.DELETE("", r.deleteListenKeyHandler)
+
+	// the stream is authenticated by the listen key
+	v1.GET("/userdata/ws", r.getUserDataWSHandler)
 
 	order := v1.Group("/order")
 	order.Use(r.authTokenMiddleware())
 	order.POST("/create", r.postOrderCreateHandler)
+	order.POST("/oco", r.postOrderOCOHandler)
 	order.GET("/get", r.getOrderGetHandler)
 	order.POST("/cancel", r.postOrderCancelHandler)
 	order.GET("/list", r.getOrderListHandler)
+	order.GET("/trades", r.getOrderTradesHandler)
 
 	position := v1.Group("/position")
 	position.Use(r.authTokenMiddleware())
@@ -71,11 +112,15 @@ func (r *Routes) Handler() http.Handler {
 	position.POST("/mode", r.postPositionModeHandler)
 	position.POST("/type", r.postPositionTypeHandler)
 	position.POST("/leverage", r.postPositionLeverageHandler)
+	position.POST("/tpsl", r.postPositionTPSLHandler)
 
 	transaction := v1.Group("/transaction")
 	transaction.Use(r.authTokenMiddleware())
 	transaction.GET("/list", r.getTransactionListHandler)
 
+	// Binance compatible api for the bots written against Binance
+	r.binanceRoutes(g)
+
 	return g
 }
 
@@ -178,7 +223,7 @@ func (r *Routes) postAPIKeyCreateHandler(c *gin.Context) {
 		return
 	}
 
-	result, err := r.usecase.CreateToken(c.Request.Context(), req.Service, req.UserID)
+	result, err := r.usecase.CreateToken(c.Request.Context(), req.Service, req.UserID, req.Settings())
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": false,
@@ -221,6 +266,156 @@ func (r *Routes) postAPIKeyDisableHandler(c *gin.Context) {
 	})
 }
 
+func (r *Routes) getAPIKeyListHandler(c *gin.Context) {
+	service := c.Query("service")
+	userID := c.Query("user_id")
+
+	result, err := r.usecase.KeysList(c.Request.Context(), service, userID)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) postAPIKeyUpdateHandler(c *gin.Context) {
+	var req UpdateTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result, err := r.usecase.UpdateToken(c.Request.Context(), entities.Token(req.Token), req.Settings())
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) postAPIKeySecretHandler(c *gin.Context) {
+	var req RotateTokenSecretRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result, err := r.usecase.RotateTokenSecret(c.Request.Context(), entities.Token(req.Token))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) postAccountTierHandler(c *gin.Context) {
+	var req AccountTierRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result, err := r.usecase.SetAccountFeeTier(c.Request.Context(), req.Service, req.UserID, req.Tier)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) getInsuranceBalanceHandler(c *gin.Context) {
+	exchange := c.Query("exchange")
+	coin := c.Query("coin")
+
+	result, err := r.usecase.InsuranceFundBalance(c.Request.Context(), entities.Exchange(exchange), entities.Coin(coin))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) postInsuranceDepositHandler(c *gin.Context) {
+	var req DepositRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result, err := r.usecase.InsuranceFundDeposit(c.Request.Context(), entities.Exchange(req.Exchange), entities.Coin(req.Coin), req.Amount)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
 func (r *Routes) getWalletBalancesHandler(c *gin.Context) {
 	exchange := c.Query("exchange")
 	accountUID, exists := c.Get("accountUID")
@@ -323,6 +518,239 @@ func (r *Routes) postWalletWithdrawHandler(c *gin.Context) {
 	})
 }
 
+func (r *Routes) postWalletTransferHandler(c *gin.Context) {
+	var req TransferRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	err := r.usecase.Transfer(c.Request.Context(), accountUID.(entities.AccountUID), entities.Coin(req.Coin), entities.Exchange(req.From), entities.Exchange(req.To), req.Amount)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) postAccountResetHandler(c *gin.Context) {
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	err := r.usecase.ResetAccount(c.Request.Context(), accountUID.(entities.AccountUID))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) postSubAccountCreateHandler(c *gin.Context) {
+	var req SubAccountCreateRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	account, key, err := r.usecase.CreateSubAccount(c.Request.Context(), accountUID.(entities.AccountUID), req.Label)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return": gin.H{
+			"account": account,
+			"key":     key,
+		},
+		"time": time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) postSubAccountTokenHandler(c *gin.Context) {
+	var req SubAccountTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	key, err := r.usecase.CreateSubAccountToken(c.Request.Context(), accountUID.(entities.AccountUID), entities.AccountUID(req.AccountUID))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  key,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) getSubAccountListHandler(c *gin.Context) {
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	result, err := r.usecase.SubAccountsList(c.Request.Context(), accountUID.(entities.AccountUID))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) postSubAccountTransferHandler(c *gin.Context) {
+	var req SubAccountTransferRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	err := r.usecase.SubAccountTransfer(c.Request.Context(), accountUID.(entities.AccountUID), entities.AccountUID(req.From), entities.AccountUID(req.To), entities.Exchange(req.Exchange), entities.Coin(req.Coin), req.Amount)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) getSubAccountBalancesHandler(c *gin.Context) {
+	exchange := c.Query("exchange")
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	result, err := r.usecase.SubAccountBalances(c.Request.Context(), entities.Exchange(exchange), accountUID.(entities.AccountUID))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
 func (r *Routes) postOrderCreateHandler(c *gin.Context) {
 	var req OrderCreateRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -343,6 +771,10 @@ func (r *Routes) postOrderCreateHandler(c *gin.Context) {
 		return
 	}
 
+	if !r.checkScope(c, entities.TradeScope(entities.Exchange(req.Exchange))) {
+		return
+	}
+
 	order := entities.NewOrder(accountUID.(entities.AccountUID))
 	order.Exchange = entities.Exchange(req.Exchange)
 	order.Symbol = entities.Symbol(req.Symbol)
@@ -351,7 +783,11 @@ func (r *Routes) postOrderCreateHandler(c *gin.Context) {
 	order.Side = entities.OrderSide(req.Side)
 	order.Amount = req.Amount
 	order.Price = req.Price
+	order.StopPrice = req.StopPrice
+	order.CallbackRate = req.CallbackRate
+	order.ActivationPrice = req.ActivationPrice
 	order.ReduceOnly = req.ReduceOnly
+	order.TimeInForce = entities.TimeInForce(req.TimeInForce)
 
 	err := r.usecase.NewOrder(c.Request.Context(), order)
 	if err != nil {
@@ -370,6 +806,64 @@ func (r *Routes) postOrderCreateHandler(c *gin.Context) {
 	})
 }
 
+func (r *Routes) postOrderOCOHandler(c *gin.Context) {
+	var req OrderOCORequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	if !r.checkScope(c, entities.TradeScope(entities.Exchange(req.Exchange))) {
+		return
+	}
+
+	limit := entities.NewOrder(accountUID.(entities.AccountUID))
+	limit.Exchange = entities.Exchange(req.Exchange)
+	limit.Symbol = entities.Symbol(req.Symbol)
+	limit.Type = entities.OrderTypeLimit
+	limit.Side = entities.OrderSide(req.Side)
+	limit.Amount = req.Amount
+	limit.Price = req.Price
+
+	stop := entities.NewOrder(accountUID.(entities.AccountUID))
+	stop.Exchange = entities.Exchange(req.Exchange)
+	stop.Symbol = entities.Symbol(req.Symbol)
+	stop.Type = entities.OrderTypeStopLimit
+	stop.Side = entities.OrderSide(req.Side)
+	stop.Amount = req.Amount
+	stop.Price = req.StopLimitPrice
+	stop.StopPrice = req.StopPrice
+
+	err := r.usecase.NewOCOOrder(c.Request.Context(), limit, stop)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  []*entities.Order{limit, stop},
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
 func (r *Routes) getOrderGetHandler(c *gin.Context) {
 	exchange := c.Query("exchange")
 	orderUID := c.Query("order_uid")
@@ -421,6 +915,10 @@ func (r *Routes) postOrderCancelHandler(c *gin.Context) {
 		return
 	}
 
+	if !r.checkScope(c, entities.TradeScope(entities.Exchange(req.Exchange))) {
+		return
+	}
+
 	result, err := r.usecase.CancelOrder(c.Request.Context(), entities.Exchange(req.Exchange), accountUID.(entities.AccountUID), req.OrderUID)
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
@@ -466,7 +964,7 @@ func (r *Routes) getOrderListHandler(c *gin.Context) {
 	var statuses []entities.OrderStatus
 	switch queryStatus {
 	case "open":
-		statuses = []entities.OrderStatus{entities.OrderStatusNew, entities.OrderStatusPending}
+		statuses = []entities.OrderStatus{entities.OrderStatusNew, entities.OrderStatusPending, entities.OrderStatusPartiallyFilled}
 	case "close":
 		statuses = []entities.OrderStatus{entities.OrderStatusSuccess, entities.OrderStatusCancelled, entities.OrderStatusFailed}
 	}
@@ -488,6 +986,72 @@ func (r *Routes) getOrderListHandler(c *gin.Context) {
 	})
 }
 
+func (r *Routes) getOrderTradesHandler(c *gin.Context) {
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	exchange := c.Query("exchange")
+
+	queryFrom := c.Query("from")
+	queryTo := c.Query("to")
+	queryLimit := c.Query("limit")
+
+	var from, to, limit int64
+	var err error
+
+	if queryFrom != "" {
+		from, err = strconv.ParseInt(queryFrom, 10, 64)
+	}
+
+	if queryTo != "" {
+		to, err = strconv.ParseInt(queryTo, 10, 64)
+	}
+
+	if queryLimit != "" {
+		limit, err = strconv.ParseInt(queryLimit, 10, 64)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	filter := entities.TradeFilter{
+		OrderUID: c.Query("order_uid"),
+		Symbol:   c.Query("symbol"),
+		From:     from,
+		To:       to,
+		Limit:    limit,
+	}
+
+	result, err := r.usecase.TradesList(c.Request.Context(), entities.Exchange(exchange), accountUID.(entities.AccountUID), filter)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
 func (r *Routes) getPositionListHandler(c *gin.Context) {
 	accountUID, exists := c.Get("accountUID")
 	if !exists {
@@ -538,6 +1102,10 @@ func (r *Routes) postPositionModeHandler(c *gin.Context) {
 		return
 	}
 
+	if !r.checkScope(c, entities.TradeScope(entities.Exchange(req.Exchange))) {
+		return
+	}
+
 	err := r.usecase.SetAccountPositionMode(c.Request.Context(), entities.Exchange(req.Exchange), accountUID.(entities.AccountUID), entities.PositionMode(req.Mode))
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
@@ -574,6 +1142,10 @@ func (r *Routes) postPositionTypeHandler(c *gin.Context) {
 		return
 	}
 
+	if !r.checkScope(c, entities.TradeScope(entities.Exchange(req.Exchange))) {
+		return
+	}
+
 	err := r.usecase.SetPositionMarginType(c.Request.Context(), entities.Exchange(req.Exchange), accountUID.(entities.AccountUID), entities.Symbol(req.Symbol), entities.MarginType(req.Type))
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
@@ -610,6 +1182,10 @@ func (r *Routes) postPositionLeverageHandler(c *gin.Context) {
 		return
 	}
 
+	if !r.checkScope(c, entities.TradeScope(entities.Exchange(req.Exchange))) {
+		return
+	}
+
 	err := r.usecase.SetPositionLeverage(c.Request.Context(), entities.Exchange(req.Exchange), accountUID.(entities.AccountUID), entities.Symbol(req.Symbol), entities.PositionLeverage(req.Leverage))
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
@@ -626,6 +1202,46 @@ func (r *Routes) postPositionLeverageHandler(c *gin.Context) {
 	})
 }
 
+func (r *Routes) postPositionTPSLHandler(c *gin.Context) {
+	var req PositionTPSLRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	if !r.checkScope(c, entities.TradeScope(entities.Exchange(req.Exchange))) {
+		return
+	}
+
+	err := r.usecase.SetPositionTPSL(c.Request.Context(), entities.Exchange(req.Exchange), accountUID.(entities.AccountUID), entities.Symbol(req.Symbol), entities.PositionSide(req.PositionSide), req.TakeProfit, req.StopLoss)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
 func (r *Routes) getTransactionListHandler(c *gin.Context) {
 	accountUID, exists := c.Get("accountUID")
 	if !exists {
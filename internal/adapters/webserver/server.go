@@ -19,6 +19,7 @@ type Config struct {
 	CertCrt            string
 	CertKey            string
 	AllowServiceTokens []string
+	TrustedProxies     []string
 }
 
 type Server struct {
@@ -26,6 +27,7 @@ type Server struct {
 	markets   Markets
 	tickers   Tickers
 	orderbook Orderbook
+	stream    MarketStream
 	usecase   Usecase
 	log       Logger
 
@@ -33,12 +35,13 @@ type Server struct {
 	srvTLS *http.Server
 }
 
-func New(cfg Config, markets Markets, tickers Tickers, orderbook Orderbook, usecase Usecase, log Logger) (*Server, error) {
+func New(cfg Config, markets Markets, tickers Tickers, orderbook Orderbook, stream MarketStream, usecase Usecase, log Logger) (*Server, error) {
 	s := Server{
 		cfg:       cfg,
 		markets:   markets,
 		tickers:   tickers,
 		orderbook: orderbook,
+		stream:    stream,
 		usecase:   usecase,
 		log:       log,
 	}
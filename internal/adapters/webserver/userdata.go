@@ -0,0 +1,225 @@
+package webserver
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"DemoExchange/internal/app/entities"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = 30 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+func (r *Routes) postListenKeyHandler(c *gin.Context) {
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	result := r.usecase.CreateListenKey(c.Request.Context(), accountUID.(entities.AccountUID))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) putListenKeyHandler(c *gin.Context) {
+	var req ListenKeyRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	result, err := r.usecase.KeepAliveListenKey(c.Request.Context(), accountUID.(entities.AccountUID), req.ListenKey)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"return":  result,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+func (r *Routes) deleteListenKeyHandler(c *gin.Context) {
+	var req ListenKeyRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	accountUID, exists := c.Get("accountUID")
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "Token not found",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	err := r.usecase.DeleteListenKey(c.Request.Context(), accountUID.(entities.AccountUID), req.ListenKey)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"time":    time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+// getUserDataWSHandler streams the order, position and balance changes of the listen key account,
+// a snapshot of the account is sent first so a reconnected client does not miss anything
+func (r *Routes) getUserDataWSHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	key, err := r.usecase.GetListenKey(ctx, c.Query("listenKey"))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		r.log.Errorf("getUserDataWSHandler:Upgrade error: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// the subscription is made before the snapshot is read, so no change is lost in between
+	events, unsubscribe := r.usecase.SubscribeUserData(ctx, key.AccountUID)
+	defer unsubscribe()
+
+	snapshot, err := r.usecase.UserDataSnapshot(ctx, key.AccountUID)
+	if err != nil {
+		r.log.Errorf("getUserDataWSHandler:UserDataSnapshot [account_uid: %s] error: %v", key.AccountUID, err)
+		return
+	}
+
+	if err := writeUserEvent(conn, entities.NewUserEvent(entities.UserEventSnapshot, "", snapshot)); err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	pongs := make(chan struct{}, 1)
+
+	go func() {
+		defer close(done)
+
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+			// clients without control frames send a text ping
+			if strings.EqualFold(strings.TrimSpace(string(message)), "ping") {
+				select {
+				case pongs <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeUserEvent(conn, event); err != nil {
+				return
+			}
+
+		case <-pongs:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.TextMessage, []byte("pong")); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			if _, err := r.usecase.GetListenKey(ctx, key.Key); err != nil {
+				writeUserEvent(conn, entities.NewUserEvent(entities.UserEventKeyExpiry, "", nil))
+				return
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeUserEvent(conn *websocket.Conn, event *entities.UserEvent) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteJSON(event)
+}
@@ -10,6 +10,7 @@ import (
 	"DemoExchange/internal/adapters/webserver"
 	"DemoExchange/internal/app/entities"
 	"DemoExchange/internal/app/markets"
+	"DemoExchange/internal/app/marketstream"
 	"DemoExchange/internal/app/orderbook"
 	"DemoExchange/internal/app/tickers"
 	"DemoExchange/internal/app/usecase"
@@ -24,6 +25,7 @@ type App struct {
 	markets   *markets.Service
 	tickers   *tickers.Service
 	orderbook *orderbook.Service
+	stream    *marketstream.Service
 	usecase   *usecase.Usecase
 	webserver *webserver.Server
 	log       *logger.Logger
@@ -71,17 +73,84 @@ func New() (*App, error) {
 	tickersService := tickers.NewService(apiclient, log)
 	orderbookService := orderbook.NewService(apiclient, log)
 
+	feeTiers := make([]entities.FeeTier, 0, len(cfg.FeeTiers.Tiers))
+	for _, tier := range cfg.FeeTiers.Tiers {
+		feeTiers = append(feeTiers, entities.FeeTier{
+			Name:    tier.Name,
+			Volume:  tier.Volume,
+			Spot:    entities.FeeRates{Maker: tier.Spot.Maker, Taker: tier.Spot.Taker},
+			Futures: entities.FeeRates{Maker: tier.Futures.Maker, Taker: tier.Futures.Taker},
+		})
+	}
+
+	marginBrackets := func(brackets []config.MarginBracket) entities.MarginBrackets {
+		result := make([]entities.MarginBracket, 0, len(brackets))
+		for _, bracket := range brackets {
+			result = append(result, entities.MarginBracket{
+				Notional:    bracket.Notional,
+				MaxLeverage: entities.PositionLeverage(bracket.MaxLeverage),
+				MMR:         bracket.MMR,
+			})
+		}
+		return entities.NewMarginBrackets(result)
+	}
+
+	symbolBrackets := make(map[entities.Symbol]entities.MarginBrackets, len(cfg.Margin.Symbols))
+	for symbol, brackets := range cfg.Margin.Symbols {
+		symbolBrackets[entities.Symbol(symbol)] = marginBrackets(brackets)
+	}
+
+	walletLimits := func(balances config.Balances) entities.WalletLimits {
+		limits := entities.WalletLimits{
+			Initial: make(map[entities.Exchange]map[entities.Coin]float64, len(balances.Initial)),
+			Max:     make(map[entities.Coin]float64, len(balances.Max)),
+		}
+		for exchange, coins := range balances.Initial {
+			initial := make(map[entities.Coin]float64, len(coins))
+			for coin, amount := range coins {
+				initial[entities.Coin(coin)] = amount
+			}
+			limits.Initial[entities.Exchange(exchange)] = initial
+		}
+		for coin, amount := range balances.Max {
+			limits.Max[entities.Coin(coin)] = amount
+		}
+		return limits
+	}
+
+	serviceWalletLimits := make(map[string]entities.WalletLimits, len(cfg.Balances.Services))
+	for service, balances := range cfg.Balances.Services {
+		serviceWalletLimits[service] = walletLimits(balances)
+	}
+
+	haircuts := make(map[entities.Coin]float64, len(cfg.Margin.Haircuts))
+	for coin, haircut := range cfg.Margin.Haircuts {
+		haircuts[entities.Coin(coin)] = haircut
+	}
+
 	cfgUsecase := usecase.Config{
-		KeyLimit: cfg.Service.KeyLimit,
-		MaxBalances: map[entities.Coin]float64{
-			"USDT": 5000,
-		},
+		KeyLimit:          cfg.Service.KeyLimit,
+		SubAccountLimit:   cfg.Service.SubAccountLimit,
+		UnsignedKeysUntil: cfg.Service.UnsignedKeysUntil,
+
+		WalletLimits:        walletLimits(cfg.Balances.Balances),
+		ServiceWalletLimits: serviceWalletLimits,
+
+		FeeTiers:           feeTiers,
+		FeeTierAutoUpgrade: cfg.FeeTiers.AutoUpgrade,
+
+		MarginBrackets:       marginBrackets(cfg.Margin.Brackets),
+		SymbolMarginBrackets: symbolBrackets,
+		LiquidationFee:       cfg.Margin.LiquidationFee,
+		CollateralHaircuts:   haircuts,
 	}
 
 	tickers := tickers.New()
 	markets := markets.New()
 
-	usecase := usecase.New(cfgUsecase, repo, tickers, markets, log)
+	usecase := usecase.New(cfgUsecase, repo, tickers, markets, orderbookService, log)
+
+	stream := marketstream.New(tickers, markets, orderbookService, log)
 
 	webserver, err := webserver.New(
 		webserver.Config{
@@ -92,10 +161,12 @@ func New() (*App, error) {
 			CertCrt:            cfg.WebServer.CertCrt,
 			CertKey:            cfg.WebServer.CertKey,
 			AllowServiceTokens: cfg.WebServer.AllowServiceTokens,
+			TrustedProxies:     cfg.WebServer.TrustedProxies,
 		},
 		markets,
 		tickers,
 		orderbookService,
+		stream,
 		usecase,
 		log,
 	)
@@ -108,6 +179,7 @@ func New() (*App, error) {
 		pool:      repo,
 		usecase:   usecase,
 		webserver: webserver,
+		stream:    stream,
 		markets:   marketsService,
 		tickers:   tickersService,
 		orderbook: orderbookService,
@@ -131,6 +203,9 @@ func (a *App) Run(ctx context.Context, cancel context.CancelFunc) error {
 
 	go a.usecase.ProcessOrders(ctx)
 	go a.usecase.ProcessPositions(ctx)
+	go a.usecase.ProcessFunding(ctx)
+	go a.usecase.ProcessUserData(ctx)
+	go a.stream.Process(ctx)
 
 	go func() {
 		err := a.webserver.Start(ctx)
@@ -15,6 +15,7 @@ var (
 	ErrSetPositionMode             = New("Error set position mode")
 	ErrSetLeverage                 = New("Error set leverage")
 	ErrSetMarginType               = New("Error set margin type")
+	ErrSetTPSL                     = New("Error set take profit / stop loss")
 	ErrOpenOrdersExists            = New("Open orders exists")
 	ErrPositionExists              = New("Position exists")
 	ErrOrderAlreadyCancelled       = New("Order already cancelled")
@@ -23,9 +24,37 @@ var (
 	ErrAmountIsNotValid            = New("Amount is not valid")
 	ErrAmountIsOutOfRange          = New("Amount is out of range")
 	ErrPriceIsNotValid             = New("Price is not valid")
+	ErrStopPriceIsNotValid         = New("Stop price is not valid")
+	ErrStopPriceWouldTrigger       = New("Stop price would trigger immediately")
+	ErrCallbackRateIsNotValid      = New("Callback rate is not valid")
+	ErrOCOPricesIsNotValid         = New("OCO prices is not valid")
+	ErrTimeInForceIsNotValid       = New("Time in force is not valid")
+	ErrOrderWouldNotFill           = New("Order would not be filled")
+	ErrOrderWouldImmediatelyMatch  = New("Order would immediately match and take")
+	ErrOrderbookIsWrong            = New("Orderbook is wrong")
+	ErrFeeTierNotFound             = New("Fee tier not found")
+	ErrLeverageIsOutOfRange        = New("Leverage is out of range")
 	ErrMarketPriceIsWrong          = New("Market price is wrong")
 	ErrOrderTypeNotValid           = New("Order Type not valid")
 	ErrOrderSideIsNotValid         = New("Order Side is not valid")
 	ErrOrderPositionModeIsNotValid = New("Order Position mode is not valid")
 	ErrOrderNotFound               = New("Order not found")
+	ErrDuplicateOrder              = New("Duplicate order")
+	ErrSubAccountNotFound          = New("Sub-account not found")
+	ErrSubAccountLimitExceeded     = New("Sub-account limit exceeded")
+	ErrNotMasterAccount            = New("Not a master account")
+	ErrTransferIsNotValid          = New("Transfer is not valid")
+	ErrAccountResetting            = New("Account reset is in progress")
+	ErrListenKeyNotFound           = New("Listen key not found")
+	ErrSignatureNotValid           = New("Signature for this request is not valid")
+	ErrTimestampIsNotValid         = New("Timestamp is not valid")
+	ErrTimestampOutsideRecvWindow  = New("Timestamp for this request is outside of the recvWindow")
+	ErrRecvWindowIsNotValid        = New("recvWindow must be less than 60000")
+	ErrRequestReplayed             = New("Request was already received")
+	ErrTokenExpired                = New("Token expired")
+	ErrIPNotAllowed                = New("IP address is not allowed")
+	ErrPermissionDenied            = New("Permission denied for the key")
+	ErrKeyScopeIsNotValid          = New("Key scope is not valid")
+	ErrKeyExpireIsNotValid         = New("Key expire time is not valid")
+	ErrIPWhitelistIsNotValid       = New("IP whitelist is not valid")
 )
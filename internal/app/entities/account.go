@@ -15,9 +15,15 @@ type Account struct {
 	Service      string       `json:"service" db:"service"`
 	UserID       string       `json:"user_id" db:"user_id"`
 	PositionMode PositionMode `json:"position_mode" db:"position_mode"`
-	CreateTS     int64        `json:"create_ts" db:"create_ts"`
-	UpdateTS     int64        `json:"update_ts" db:"update_ts"`
-	IsNew        bool         `json:"-" db:"-"`
+	// FeeTier is kept by the volume upgrade unless it is pinned by an admin
+	FeeTier       string `json:"fee_tier" db:"fee_tier"`
+	FeeTierPinned bool   `json:"fee_tier_pinned" db:"fee_tier_pinned"`
+	// MasterUID is set for a sub-account, it shares the service and the user of its master
+	MasterUID AccountUID `json:"master_uid,omitempty" db:"master_uid"`
+	Label     string     `json:"label,omitempty" db:"label"`
+	CreateTS  int64      `json:"create_ts" db:"create_ts"`
+	UpdateTS  int64      `json:"update_ts" db:"update_ts"`
+	IsNew     bool       `json:"-" db:"-"`
 }
 
 type AccountUID string
@@ -35,6 +41,18 @@ func NewAccount(service, userID string) *Account {
 	}
 }
 
+func NewSubAccount(master *Account, label string) *Account {
+	account := NewAccount(master.Service, master.UserID)
+	account.MasterUID = master.AccountUID
+	account.Label = label
+
+	return account
+}
+
+func (a *Account) IsSub() bool {
+	return a.MasterUID != ""
+}
+
 func TS() int64 {
 	return time.Now().UTC().UnixMilli()
 }
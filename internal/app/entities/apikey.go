@@ -1,28 +1,259 @@
 package entities
 
 import (
+	"net"
+	"strings"
 	"time"
 
+	"DemoExchange/internal/app/apperror"
 	"DemoExchange/internal/app/pkg/hash"
 )
 
+const (
+	DefaultRecvWindow int64 = 5000
+	MaxRecvWindow     int64 = 60000
+	// MaxClockAhead is how far the client clock may run ahead of the server
+	MaxClockAhead int64 = 1000
+
+	lenSecret = 32
+)
+
 type Key struct {
 	Token      Token      `json:"token" db:"token"`
 	AccountUID AccountUID `json:"account_uid" db:"account_uid"`
-	Disabled   bool       `json:"disabled" db:"disabled"`
-	CreateTS   int64      `json:"create_ts" db:"create_ts"`
-	UpdateTS   int64      `json:"update_ts" db:"update_ts"`
+	// Secret signs the private requests, it is returned only when the key is created or its secret is rotated
+	Secret string `json:"secret,omitempty" db:"secret"`
+	// Unsigned marks a key issued before the requests were signed, it is accepted without a signature
+	// within the grace period of the config until its secret is rotated
+	Unsigned bool       `json:"unsigned" db:"unsigned"`
+	Label    string     `json:"label" db:"label"`
+	Scopes   []KeyScope `json:"scopes" db:"scopes"`
+	// ExpireTS is the time the key stops working, 0 is never
+	ExpireTS int64 `json:"expire_ts" db:"expire_ts"`
+	// IPWhitelist are the networks the key is used from, an empty list allows any address
+	IPWhitelist []string `json:"ip_whitelist" db:"ip_whitelist"`
+	Disabled    bool     `json:"disabled" db:"disabled"`
+	CreateTS    int64    `json:"create_ts" db:"create_ts"`
+	UpdateTS    int64    `json:"update_ts" db:"update_ts"`
 }
 
 type Token string
 
-func NewToken(accountUID AccountUID) *Key {
+// lenTokenMasked is how much of the token is kept when it is logged
+const lenTokenMasked = 6
+
+// Masked returns the start of the token to log it without exposing it
+func (t Token) Masked() string {
+	if len(t) <= lenTokenMasked {
+		return "***"
+	}
+
+	return string(t[:lenTokenMasked]) + "***"
+}
+
+// KeyScope is a permission of the key, any key can read the account
+type KeyScope string
+
+const (
+	KeyScopeRead    KeyScope = "read"
+	KeyScopeSpot    KeyScope = "spot"
+	KeyScopeFutures KeyScope = "futures"
+	KeyScopeWallet  KeyScope = "wallet"
+)
+
+// KeyScopes are the scopes of a key created without scopes
+var KeyScopes = []KeyScope{KeyScopeRead, KeyScopeSpot, KeyScopeFutures, KeyScopeWallet}
+
+func (s KeyScope) IsValid() bool {
+	for _, scope := range KeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// TradeScope is the scope to trade on the exchange
+func TradeScope(exchange Exchange) KeyScope {
+	if exchange == ExchangeFutures {
+		return KeyScopeFutures
+	}
+	return KeyScopeSpot
+}
+
+// KeySettings are the editable settings of a key, a nil field is left unchanged
+type KeySettings struct {
+	Label       *string
+	Scopes      []KeyScope
+	ExpireTS    *int64
+	IPWhitelist []string
+}
+
+func NewToken(accountUID AccountUID) (*Key, error) {
+	secret, err := NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
 	ts := TS()
 
 	return &Key{
-		Token:      Token(hash.GenSHA1(string(accountUID) + time.Now().String())),
-		AccountUID: accountUID,
-		CreateTS:   ts,
-		UpdateTS:   ts,
+		Token:       Token(hash.GenSHA1(string(accountUID) + time.Now().String())),
+		AccountUID:  accountUID,
+		Secret:      secret,
+		Scopes:      append([]KeyScope(nil), KeyScopes...),
+		IPWhitelist: []string{},
+		CreateTS:    ts,
+		UpdateTS:    ts,
+	}, nil
+}
+
+// NewSecret returns a random key secret
+func NewSecret() (string, error) {
+	return hash.GenRandom(lenSecret)
+}
+
+// Apply validates the settings and sets them on the key, a single address of the whitelist becomes a network of one host
+func (k *Key) Apply(settings KeySettings) error {
+	if settings.Scopes != nil {
+		if len(settings.Scopes) == 0 {
+			return apperror.ErrKeyScopeIsNotValid
+		}
+		for _, scope := range settings.Scopes {
+			if !scope.IsValid() {
+				return apperror.ErrKeyScopeIsNotValid
+			}
+		}
+	}
+
+	if settings.ExpireTS != nil && *settings.ExpireTS < 0 {
+		return apperror.ErrKeyExpireIsNotValid
+	}
+
+	var whitelist []string
+	if settings.IPWhitelist != nil {
+		whitelist = make([]string, 0, len(settings.IPWhitelist))
+		for _, item := range settings.IPWhitelist {
+			network, err := parseNetwork(item)
+			if err != nil {
+				return apperror.ErrIPWhitelistIsNotValid
+			}
+			whitelist = append(whitelist, network.String())
+		}
+	}
+
+	if settings.Label != nil {
+		k.Label = *settings.Label
+	}
+	if settings.Scopes != nil {
+		k.Scopes = settings.Scopes
+	}
+	if settings.ExpireTS != nil {
+		k.ExpireTS = *settings.ExpireTS
+	}
+	if whitelist != nil {
+		k.IPWhitelist = whitelist
+	}
+
+	return nil
+}
+
+// HasScope reports whether the key is allowed the scope
+func (k *Key) HasScope(scope KeyScope) bool {
+	if scope == KeyScopeRead {
+		return true
+	}
+
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *Key) IsExpired(ts int64) bool {
+	return k.ExpireTS > 0 && ts >= k.ExpireTS
+}
+
+// AllowsIP reports whether the key can be used from the address
+func (k *Key) AllowsIP(address string) bool {
+	if len(k.IPWhitelist) == 0 {
+		return true
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, item := range k.IPWhitelist {
+		if network, err := parseNetwork(item); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetwork(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, apperror.ErrIPWhitelistIsNotValid
+		}
+		if ip.To4() != nil {
+			s += "/32"
+		} else {
+			s += "/128"
+		}
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	return network, err
+}
+
+// SignedRequest is a private request, the signature is the HMAC-SHA256 of the payload keyed by the key secret
+type SignedRequest struct {
+	Token Token
+	// IP is the address the request came from
+	IP         string
+	Payload    string
+	Signature  string
+	Timestamp  int64
+	RecvWindow int64
+}
+
+// Validate checks the request is signed and was sent within its receive window before the server time ts
+func (r *SignedRequest) Validate(ts int64) error {
+	if r.Signature == "" {
+		return apperror.ErrSignatureNotValid
+	}
+
+	if r.Timestamp <= 0 {
+		return apperror.ErrTimestampIsNotValid
+	}
+
+	if r.RecvWindow == 0 {
+		r.RecvWindow = DefaultRecvWindow
 	}
+
+	if r.RecvWindow < 0 || r.RecvWindow > MaxRecvWindow {
+		return apperror.ErrRecvWindowIsNotValid
+	}
+
+	if r.Timestamp > ts+MaxClockAhead || ts-r.Timestamp > r.RecvWindow {
+		return apperror.ErrTimestampOutsideRecvWindow
+	}
+
+	return nil
+}
+
+// Verify checks the signature with the secret, a key without a secret can not sign
+func (r *SignedRequest) Verify(secret string) bool {
+	return secret != "" && hash.CheckHmacSHA256(secret, r.Payload, r.Signature)
+}
+
+// ExpireTS is the time the request stops being accepted, a replay is rejected until then
+func (r *SignedRequest) ExpireTS() int64 {
+	return r.Timestamp + r.RecvWindow
 }
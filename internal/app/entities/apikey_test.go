@@ -0,0 +1,128 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/pkg/hash"
+)
+
+func TestSignedRequestValidate(t *testing.T) {
+	const ts int64 = 1700000000000
+
+	cases := []struct {
+		request SignedRequest
+		err     error
+	}{
+		{SignedRequest{Signature: "s", Timestamp: ts - 1000}, nil},
+		{SignedRequest{Signature: "s", Timestamp: ts + 500}, nil},                                           // client clock slightly ahead
+		{SignedRequest{Signature: "s", Timestamp: ts - 6000}, apperror.ErrTimestampOutsideRecvWindow},       // default window passed
+		{SignedRequest{Signature: "s", Timestamp: ts - 6000, RecvWindow: 10000}, nil},                       // wider window
+		{SignedRequest{Signature: "s", Timestamp: ts + 2000}, apperror.ErrTimestampOutsideRecvWindow},       // from the future
+		{SignedRequest{Signature: "s", Timestamp: ts, RecvWindow: 70000}, apperror.ErrRecvWindowIsNotValid}, // window too wide
+		{SignedRequest{Signature: "s"}, apperror.ErrTimestampIsNotValid},
+		{SignedRequest{Timestamp: ts}, apperror.ErrSignatureNotValid},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.err, c.request.Validate(ts))
+	}
+}
+
+func TestSignedRequestVerify(t *testing.T) {
+	payload := "symbol=BTCUSDT&timestamp=1700000000000"
+	request := SignedRequest{Payload: payload, Signature: hash.GenHmacSHA256("secret", payload)}
+
+	assert.True(t, request.Verify("secret"))
+	assert.False(t, request.Verify("other"))
+
+	// a key without a secret can not sign
+	request.Signature = hash.GenHmacSHA256("", payload)
+	assert.False(t, request.Verify(""))
+}
+
+func TestKeyApply(t *testing.T) {
+	label := "dashboard"
+	expire := int64(1700000000000)
+	negative := int64(-1)
+
+	key, err := NewToken("account")
+	assert.NoError(t, err)
+	assert.Equal(t, KeyScopes, key.Scopes)
+
+	assert.NoError(t, key.Apply(KeySettings{Label: &label, Scopes: []KeyScope{KeyScopeRead}, ExpireTS: &expire, IPWhitelist: []string{"10.0.0.0/8", "192.168.1.10"}}))
+	assert.Equal(t, label, key.Label)
+	assert.Equal(t, []KeyScope{KeyScopeRead}, key.Scopes)
+	assert.Equal(t, expire, key.ExpireTS)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10/32"}, key.IPWhitelist)
+
+	// omitted settings are left unchanged
+	assert.NoError(t, key.Apply(KeySettings{}))
+	assert.Equal(t, label, key.Label)
+	assert.Equal(t, []KeyScope{KeyScopeRead}, key.Scopes)
+
+	assert.NoError(t, key.Apply(KeySettings{IPWhitelist: []string{}}))
+	assert.Empty(t, key.IPWhitelist)
+
+	cases := []struct {
+		settings KeySettings
+		err      error
+	}{
+		{KeySettings{Scopes: []KeyScope{}}, apperror.ErrKeyScopeIsNotValid},
+		{KeySettings{Scopes: []KeyScope{"admin"}}, apperror.ErrKeyScopeIsNotValid},
+		{KeySettings{ExpireTS: &negative}, apperror.ErrKeyExpireIsNotValid},
+		{KeySettings{IPWhitelist: []string{"10.0.0.0/33"}}, apperror.ErrIPWhitelistIsNotValid},
+		{KeySettings{IPWhitelist: []string{"localhost"}}, apperror.ErrIPWhitelistIsNotValid},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.err, key.Apply(c.settings))
+	}
+
+	// a rejected update changes nothing
+	other := "other"
+	assert.Error(t, key.Apply(KeySettings{Label: &other, Scopes: []KeyScope{"admin"}}))
+	assert.Equal(t, label, key.Label)
+}
+
+func TestKeyHasScope(t *testing.T) {
+	key := Key{Scopes: []KeyScope{KeyScopeRead, KeyScopeSpot}}
+
+	assert.True(t, key.HasScope(KeyScopeRead))
+	assert.True(t, key.HasScope(TradeScope(ExchangeSpot)))
+	assert.False(t, key.HasScope(TradeScope(ExchangeFutures)))
+	assert.False(t, key.HasScope(KeyScopeWallet))
+}
+
+func TestKeyIsExpired(t *testing.T) {
+	assert.False(t, (&Key{}).IsExpired(1700000000000))
+	assert.False(t, (&Key{ExpireTS: 1700000000001}).IsExpired(1700000000000))
+	assert.True(t, (&Key{ExpireTS: 1700000000000}).IsExpired(1700000000000))
+}
+
+func TestKeyAllowsIP(t *testing.T) {
+	cases := []struct {
+		whitelist []string
+		ip        string
+		allowed   bool
+	}{
+		{nil, "8.8.8.8", true}, // no whitelist
+		{[]string{"10.0.0.0/8"}, "10.1.2.3", true},
+		{[]string{"10.0.0.0/8"}, "11.1.2.3", false},
+		{[]string{"10.0.0.0/8", "192.168.1.10/32"}, "192.168.1.10", true},
+		{[]string{"2001:db8::/32"}, "2001:db8::1", true},
+		{[]string{"10.0.0.0/8"}, "not an ip", false},
+	}
+
+	for _, c := range cases {
+		key := Key{IPWhitelist: c.whitelist}
+		assert.Equal(t, c.allowed, key.AllowsIP(c.ip))
+	}
+}
+
+func TestTokenMasked(t *testing.T) {
+	assert.Equal(t, "024e5a***", Token("024e5a544c031305a7a96552d0f80620217c26a3").Masked())
+	assert.Equal(t, "***", Token("024e5a").Masked())
+}
@@ -15,3 +15,8 @@ var exchanges = map[Exchange]string{
 func (e Exchange) Name() string {
 	return exchanges[e]
 }
+
+func (e Exchange) IsValid() bool {
+	_, ok := exchanges[e]
+	return ok
+}
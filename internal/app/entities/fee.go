@@ -0,0 +1,21 @@
+package entities
+
+type FeeRates struct {
+	Maker float64 `json:"maker"`
+	Taker float64 `json:"taker"`
+}
+
+// FeeTier is a fee schedule level, Volume is the 30-day traded volume required for it
+type FeeTier struct {
+	Name    string   `json:"name"`
+	Volume  float64  `json:"volume"`
+	Spot    FeeRates `json:"spot"`
+	Futures FeeRates `json:"futures"`
+}
+
+func (t FeeTier) Rates(exchange Exchange) FeeRates {
+	if exchange == ExchangeFutures {
+		return t.Futures
+	}
+	return t.Spot
+}
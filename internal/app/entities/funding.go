@@ -0,0 +1,40 @@
+package entities
+
+// MaxFundingPeriods limits the missed funding times settled at once
+const MaxFundingPeriods = 90
+
+// Funding is the next funding time of a futures symbol with its rate and mark price,
+// SettledTS is the last funding time paid to keep the times missed while the service was down
+type Funding struct {
+	Symbol    Symbol  `json:"symbol" db:"symbol"`
+	FundingTS int64   `json:"funding_ts" db:"funding_ts"`
+	Rate      float64 `json:"rate" db:"rate"`
+	Price     float64 `json:"price" db:"price"`
+	SettledTS int64   `json:"settled_ts" db:"settled_ts"`
+	UpdateTS  int64   `json:"update_ts" db:"update_ts"`
+}
+
+// IsSettled reports whether the funding time is already paid
+func (f *Funding) IsSettled() bool {
+	return f.FundingTS <= f.SettledTS
+}
+
+// DueTimes returns the funding times up to ts not paid yet, the times missed after the next one
+// follow it at the interval from the last time paid
+func (f *Funding) DueTimes(ts int64) []int64 {
+	if f.IsSettled() || f.FundingTS > ts {
+		return nil
+	}
+
+	result := []int64{f.FundingTS}
+	if f.SettledTS == 0 {
+		return result
+	}
+
+	interval := f.FundingTS - f.SettledTS
+	for next := f.FundingTS + interval; next <= ts && len(result) < MaxFundingPeriods; next += interval {
+		result = append(result, next)
+	}
+
+	return result
+}
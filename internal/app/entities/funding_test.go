@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFundingDueTimes(t *testing.T) {
+	cases := []struct {
+		funding Funding
+		ts      int64
+		due     []int64
+	}{
+		{Funding{FundingTS: 200, SettledTS: 100}, 150, nil},                    // not due yet
+		{Funding{FundingTS: 200, SettledTS: 200}, 250, nil},                    // already paid
+		{Funding{FundingTS: 200}, 250, []int64{200}},                           // first time
+		{Funding{FundingTS: 200, SettledTS: 100}, 250, []int64{200}},           // due
+		{Funding{FundingTS: 200, SettledTS: 100}, 450, []int64{200, 300, 400}}, // missed while down
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.due, c.funding.DueTimes(c.ts))
+	}
+
+	funding := Funding{FundingTS: 2, SettledTS: 1}
+	assert.Len(t, funding.DueTimes(1000), MaxFundingPeriods)
+}
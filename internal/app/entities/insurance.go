@@ -0,0 +1,27 @@
+package entities
+
+import "github.com/google/uuid"
+
+// InsuranceFund is an entry of the insurance fund ledger of an exchange,
+// AccountUID is the account whose liquidation made the entry
+type InsuranceFund struct {
+	FundUID    string     `json:"fund_uid" db:"fund_uid"`
+	Exchange   Exchange   `json:"exchange" db:"exchange"`
+	AccountUID AccountUID `json:"account_uid" db:"account_uid"`
+	Symbol     Symbol     `json:"symbol" db:"symbol"`
+	Coin       Coin       `json:"coin" db:"coin"`
+	Amount     float64    `json:"amount" db:"amount"`
+	CreateTS   int64      `json:"create_ts" db:"create_ts"`
+}
+
+func NewInsuranceFund(accountUID AccountUID, exchange Exchange, symbol Symbol, coin Coin, amount float64) *InsuranceFund {
+	return &InsuranceFund{
+		FundUID:    uuid.New().String(),
+		Exchange:   exchange,
+		AccountUID: accountUID,
+		Symbol:     symbol,
+		Coin:       coin,
+		Amount:     amount,
+		CreateTS:   TS(),
+	}
+}
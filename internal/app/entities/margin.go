@@ -0,0 +1,122 @@
+package entities
+
+import (
+	"math"
+	"sort"
+)
+
+// MarginBracket is a notional tier of a symbol, positions up to Notional are limited to MaxLeverage
+// and keep MMR of their notional less MaintAmount as maintenance margin
+type MarginBracket struct {
+	Notional    float64          `json:"notional"`
+	MaxLeverage PositionLeverage `json:"max_leverage"`
+	MMR         float64          `json:"mmr"`
+	MaintAmount float64          `json:"maint_amount"`
+}
+
+type MarginBrackets []MarginBracket
+
+// NewMarginBrackets sorts the tiers by notional and fills the maintenance amounts
+// that keep the maintenance margin continuous on the tier bounds
+func NewMarginBrackets(brackets []MarginBracket) MarginBrackets {
+	result := make(MarginBrackets, len(brackets))
+	copy(result, brackets)
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Notional < result[j].Notional
+	})
+
+	for i := 1; i < len(result); i++ {
+		result[i].MaintAmount = result[i-1].MaintAmount + result[i-1].Notional*(result[i].MMR-result[i-1].MMR)
+	}
+
+	return result
+}
+
+// Find returns the bracket of the notional, the last bracket covers everything above it
+func (b MarginBrackets) Find(notional float64) MarginBracket {
+	for _, bracket := range b {
+		if notional <= bracket.Notional {
+			return bracket
+		}
+	}
+
+	if len(b) == 0 {
+		return MarginBracket{}
+	}
+
+	return b[len(b)-1]
+}
+
+// MaintMargin returns the maintenance margin of the notional in the bracket
+func (b MarginBracket) MaintMargin(notional float64) float64 {
+	margin := notional*b.MMR - b.MaintAmount
+	if margin < 0 {
+		return 0
+	}
+
+	return margin
+}
+
+// CrossMargin is the margin shared by the cross positions of an account settled in one coin,
+// WalletBalance is the wallet total together with the margins of the positions
+type CrossMargin struct {
+	WalletBalance float64 `json:"wallet_balance"`
+	UnrealisedPnl float64 `json:"unrealised_pnl"`
+	MaintMargin   float64 `json:"maint_margin"`
+}
+
+// NewCrossMargin sums the positions, their margin balance and maintenance margin must be calculated
+func NewCrossMargin(total float64, positions []*Position) CrossMargin {
+	c := CrossMargin{
+		WalletBalance: total,
+	}
+
+	for _, p := range positions {
+		c.WalletBalance += p.Margin
+		c.UnrealisedPnl += p.UnrealisedPnl
+		c.MaintMargin += p.MaintMargin
+	}
+
+	return c
+}
+
+func (c CrossMargin) MarginBalance() float64 {
+	return c.WalletBalance + c.UnrealisedPnl
+}
+
+// IsLiquidated reports whether the margin balance has fallen to the maintenance margin of the positions
+func (c CrossMargin) IsLiquidated() bool {
+	return c.MarginBalance() <= c.MaintMargin
+}
+
+// Balance returns the balance backing the position, that is the wallet balance
+// with the pnl and less the maintenance margin of the other positions
+func (c CrossMargin) Balance(p *Position) float64 {
+	return c.WalletBalance + (c.UnrealisedPnl - p.UnrealisedPnl) - (c.MaintMargin - p.MaintMargin)
+}
+
+// ADLRank returns the quintile of the score among the scores of the auto-deleveraging queue,
+// from 1 to 5 where 5 is deleveraged first, and 0 when the score is out of the queue
+func ADLRank(score float64, scores []float64) int {
+	if score <= 0 || len(scores) == 0 {
+		return 0
+	}
+
+	n := 0
+	for _, s := range scores {
+		if s <= score {
+			n++
+		}
+	}
+
+	rank := int(math.Ceil(float64(n) / float64(len(scores)) * 5))
+	if rank < 1 {
+		return 1
+	}
+	if rank > 5 {
+		return 5
+	}
+
+	return rank
+}
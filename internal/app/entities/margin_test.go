@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarginBrackets(t *testing.T) {
+	brackets := NewMarginBrackets([]MarginBracket{
+		{Notional: 1000000, MaxLeverage: 50, MMR: 0.01},
+		{Notional: 50000, MaxLeverage: 125, MMR: 0.004},
+		{Notional: 250000, MaxLeverage: 100, MMR: 0.005},
+	})
+
+	cases := []struct {
+		notional    float64
+		leverage    PositionLeverage
+		maintMargin float64
+	}{
+		{10000, 125, 40},     // first bracket
+		{100000, 100, 450},   // 100000*0.005 - 50
+		{250000, 100, 1200},  // upper bound of the bracket
+		{5000000, 50, 48700}, // above the last bracket, 5000000*0.01 - 1300
+	}
+
+	for _, c := range cases {
+		bracket := brackets.Find(c.notional)
+		assert.Equal(t, c.leverage, bracket.MaxLeverage)
+		assert.InDelta(t, c.maintMargin, bracket.MaintMargin(c.notional), 1e-6)
+	}
+
+	assert.Equal(t, MarginBracket{}, MarginBrackets{}.Find(1000))
+}
+
+func TestCrossMargin(t *testing.T) {
+	positions := []*Position{
+		{Margin: 100, UnrealisedPnl: -150, MaintMargin: 10},
+		{Margin: 200, UnrealisedPnl: 50, MaintMargin: 20},
+	}
+
+	cross := NewCrossMargin(1000, positions)
+	assert.Equal(t, CrossMargin{WalletBalance: 1300, UnrealisedPnl: -100, MaintMargin: 30}, cross)
+	assert.Equal(t, float64(1200), cross.MarginBalance())
+	assert.False(t, cross.IsLiquidated())
+	assert.Equal(t, float64(1300+50-20), cross.Balance(positions[0]))
+
+	cross = NewCrossMargin(0, positions)
+	cross.UnrealisedPnl = -280
+	assert.True(t, cross.IsLiquidated())
+}
+
+func TestADLRank(t *testing.T) {
+	scores := []float64{1, 2, 3, 4, 5}
+
+	cases := []struct {
+		score float64
+		rank  int
+	}{
+		{5, 5},
+		{3, 3},
+		{1, 1},
+		{10, 5}, // above the queue
+		{0, 0},  // not profitable
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.rank, ADLRank(c.score, scores))
+	}
+
+	assert.Equal(t, 0, ADLRank(1, nil))
+}
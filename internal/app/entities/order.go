@@ -7,27 +7,48 @@ import (
 )
 
 type Order struct {
-	AccountUID   AccountUID       `json:"account_uid" db:"account_uid"`
-	OrderUID     string           `json:"order_uid" db:"order_uid"`
-	Exchange     Exchange         `json:"exchange" db:"exchange"`
-	Symbol       Symbol           `json:"symbol" db:"symbol"`
-	Type         OrderType        `json:"type" db:"type"`
-	Side         OrderSide        `json:"side" db:"side"`
-	Amount       float64          `json:"amount" db:"amount"`
-	Price        float64          `json:"price" db:"price"`
-	Status       OrderStatus      `json:"status" db:"status"`
-	Error        string           `json:"error,omitempty" db:"error"`
-	CreateTS     int64            `json:"create_ts" db:"create_ts"`
-	UpdateTS     int64            `json:"update_ts" db:"update_ts"`
-	PositionSide PositionSide     `json:"position_side" db:"position_side"`
-	Fee          float64          `json:"fee" db:"fee"`
-	FeeCoin      Coin             `json:"fee_coin" db:"fee_coin"`
-	ReduceOnly   bool             `json:"reduce_only" db:"reduce_only"`
-	Leverage     PositionLeverage `json:"leverage" db:"leverage"`
+	AccountUID AccountUID `json:"account_uid" db:"account_uid"`
+	OrderUID   string     `json:"order_uid" db:"order_uid"`
+	OrderID    int64      `json:"order_id" db:"order_id"`
+	// ClientOrderID is the id the client placed the order with, it is empty when none was given
+	ClientOrderID string           `json:"client_order_id,omitempty" db:"client_order_id"`
+	Exchange      Exchange         `json:"exchange" db:"exchange"`
+	Symbol        Symbol           `json:"symbol" db:"symbol"`
+	Type          OrderType        `json:"type" db:"type"`
+	Side          OrderSide        `json:"side" db:"side"`
+	Amount        float64          `json:"amount" db:"amount"`
+	Price         float64          `json:"price" db:"price"`
+	Status        OrderStatus      `json:"status" db:"status"`
+	Error         string           `json:"error,omitempty" db:"error"`
+	CreateTS      int64            `json:"create_ts" db:"create_ts"`
+	UpdateTS      int64            `json:"update_ts" db:"update_ts"`
+	PositionSide  PositionSide     `json:"position_side" db:"position_side"`
+	Fee           float64          `json:"fee" db:"fee"`
+	FeeCoin       Coin             `json:"fee_coin" db:"fee_coin"`
+	ReduceOnly    bool             `json:"reduce_only" db:"reduce_only"`
+	Leverage      PositionLeverage `json:"leverage" db:"leverage"`
+	StopPrice     float64          `json:"stop_price" db:"stop_price"`
+	Triggered     bool             `json:"triggered" db:"triggered"`
+	// CallbackRate is the trailing distance in percent, StopPrice holds the current trailing level
+	CallbackRate    float64 `json:"callback_rate" db:"callback_rate"`
+	ActivationPrice float64 `json:"activation_price" db:"activation_price"`
+	// GroupUID links the legs of an OCO order
+	GroupUID    string      `json:"group_uid,omitempty" db:"group_uid"`
+	TimeInForce TimeInForce `json:"time_in_force" db:"time_in_force"`
+	// FilledAmount and AvgPrice accumulate the fills of the order
+	FilledAmount float64 `json:"filled_amount" db:"filled_amount"`
+	AvgPrice     float64 `json:"avg_price" db:"avg_price"`
+	FeeType      FeeType `json:"fee_type,omitempty" db:"fee_type"`
+	RealizedPnl  float64 `json:"realized_pnl" db:"realized_pnl"`
 
 	PositionMode PositionMode
 	Precision    int64
 	Limit        float64
+	HoldPrice    float64
+	MakerFee     float64
+	TakerFee     float64
+	// Trade is the current fill passed from the order process to the balance settlement
+	Trade *Trade `json:"-"`
 }
 
 type Orders []Order
@@ -54,17 +75,22 @@ func (s Symbol) GetCoins() Coins {
 type OrderStatus string
 
 const (
-	OrderStatusNew       OrderStatus = "new"
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusSuccess   OrderStatus = "done"
-	OrderStatusCancelled OrderStatus = "cancelled"
-	OrderStatusFailed    OrderStatus = "failed"
+	OrderStatusNew             OrderStatus = "new"
+	OrderStatusPending         OrderStatus = "pending"
+	OrderStatusPartiallyFilled OrderStatus = "partially_filled"
+	OrderStatusSuccess         OrderStatus = "done"
+	OrderStatusCancelled       OrderStatus = "cancelled"
+	OrderStatusFailed          OrderStatus = "failed"
 )
 
 func (s OrderStatus) String() string {
 	return string(s)
 }
 
+func (s OrderStatus) IsFinal() bool {
+	return s == OrderStatusSuccess || s == OrderStatusCancelled || s == OrderStatusFailed
+}
+
 func StatusArrayToString(statuses []OrderStatus) string {
 	var statusArray []string
 	for _, status := range statuses {
@@ -76,8 +102,35 @@ func StatusArrayToString(statuses []OrderStatus) string {
 type OrderType string
 
 const (
-	OrderTypeMarket OrderType = "market"
-	OrderTypeLimit  OrderType = "limit"
+	OrderTypeMarket       OrderType = "market"
+	OrderTypeLimit        OrderType = "limit"
+	OrderTypeStopMarket   OrderType = "stop_market"
+	OrderTypeStopLimit    OrderType = "stop_limit"
+	OrderTypeTrailingStop OrderType = "trailing_stop"
+)
+
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC"
+	TimeInForceIOC TimeInForce = "IOC"
+	TimeInForceFOK TimeInForce = "FOK"
+	TimeInForceGTX TimeInForce = "GTX"
+)
+
+func (t TimeInForce) IsValid() bool {
+	switch t {
+	case TimeInForceGTC, TimeInForceIOC, TimeInForceFOK, TimeInForceGTX:
+		return true
+	}
+	return false
+}
+
+type FeeType string
+
+const (
+	FeeTypeMaker FeeType = "maker"
+	FeeTypeTaker FeeType = "taker"
 )
 
 type OrderSide string
@@ -98,3 +151,25 @@ func NewOrder(accountUID AccountUID) *Order {
 		UpdateTS:   ts,
 	}
 }
+
+// RemainingAmount returns the part of the order amount not filled yet
+func (o *Order) RemainingAmount() float64 {
+	return o.Amount - o.FilledAmount
+}
+
+// FeeRate returns the fee rate of the current fill, balance is held at the taker rate
+func (o *Order) FeeRate() float64 {
+	if o.Trade != nil && o.Trade.IsMaker {
+		return o.MakerFee
+	}
+	return o.TakerFee
+}
+
+// GetHoldPrice returns the price the balance was held at.
+// It differs from Price when the order is filled at a price other than the one it was placed with.
+func (o *Order) GetHoldPrice() float64 {
+	if o.HoldPrice > 0 {
+		return o.HoldPrice
+	}
+	return o.Price
+}
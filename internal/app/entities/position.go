@@ -1,6 +1,8 @@
 package entities
 
 import (
+	"math"
+
 	"github.com/google/uuid"
 )
 
@@ -23,11 +25,15 @@ type Position struct {
 	MarkPrice        float64          `json:"mark_price" db:"-"`
 	Margin           float64          `json:"margin" db:"margin"`
 	HoldAmount       float64          `json:"-" db:"hold_amount"`
+	TakeProfit       float64          `json:"take_profit" db:"take_profit"`
+	StopLoss         float64          `json:"stop_loss" db:"stop_loss"`
 	CreateTS         int64            `json:"create_ts" db:"create_ts"`
 	UpdateTS         int64            `json:"update_ts" db:"update_ts"`
 	IsNew            bool             `json:"-" db:"-"`
 	MarginBalance    float64          `json:"margin_balance" db:"-"`
 	UnrealisedPnl    float64          `json:"unrealised_pnl" db:"-"`
+	MaintMargin      float64          `json:"maint_margin" db:"-"`
+	ADLRank          int              `json:"adl_rank" db:"-"`
 	LiquidationPrice float64          `json:"liquidation_price" db:"-"`
 }
 
@@ -95,6 +101,97 @@ func NewPosition(account *Account, exchange Exchange, symbol Symbol, positionSid
 	}
 }
 
+// IsLong reports whether the position gains when the price rises
+func (p *Position) IsLong() bool {
+	if p.Mode == PositionModeHedge {
+		return p.Side == PositionSideLong
+	}
+
+	return p.Amount > 0
+}
+
+// CloseSide returns the order side that reduces the position
+func (p *Position) CloseSide() OrderSide {
+	if p.IsLong() {
+		return OrderSideSell
+	}
+
+	return OrderSideBuy
+}
+
+// RealizedPnl returns the pnl of closing the amount of the position at the price
+func (p *Position) RealizedPnl(amount, price float64) float64 {
+	if p.IsLong() {
+		return amount * (price - p.Price)
+	}
+
+	return amount * (p.Price - price)
+}
+
+// AddEntry sets the entry price of the position increased by the amount at the price
+// to the average weighted by the amounts, it must be called before the amount is changed
+func (p *Position) AddEntry(amount, price float64) {
+	total := math.Abs(p.Amount) + amount
+	if total == 0 {
+		return
+	}
+
+	p.Price = (math.Abs(p.Amount)*p.Price + amount*price) / total
+}
+
+// ChargeMargin takes the amount out of the position by moving its entry price against it,
+// the margin balance and the pnl of the close drop by the amount. A long without leverage can not be charged
+func (p *Position) ChargeMargin(amount float64) bool {
+	size := math.Abs(p.Amount)
+	leverage := p.Leverage.ToFloat64()
+	if size == 0 || leverage <= 0 {
+		return false
+	}
+
+	var price float64
+	if p.IsLong() {
+		if leverage <= 1 {
+			return false
+		}
+		price = p.Price + amount*leverage/(size*(leverage-1))
+	} else {
+		price = p.Price - amount*leverage/(size*(leverage+1))
+	}
+
+	if price <= 0 {
+		return false
+	}
+
+	p.Price = price
+	p.Margin = size * p.Price / leverage
+
+	return true
+}
+
+// CheckTPSL reports whether the price has crossed the take profit or the stop loss
+func (p *Position) CheckTPSL(price float64) bool {
+	if p.Amount == 0 {
+		return false
+	}
+
+	if p.IsLong() {
+		return (p.TakeProfit > 0 && price >= p.TakeProfit) || (p.StopLoss > 0 && price <= p.StopLoss)
+	}
+
+	return (p.TakeProfit > 0 && price <= p.TakeProfit) || (p.StopLoss > 0 && price >= p.StopLoss)
+}
+
+// FundingFee returns the wallet change for a funding payment at the given mark price and rate,
+// longs pay shorts when the rate is positive and receive from them when it is negative
+func (p *Position) FundingFee(price, rate float64) float64 {
+	fee := math.Abs(p.Amount) * price * rate
+	if p.IsLong() {
+		return -fee
+	}
+
+	return fee
+}
+
 func (p *Position) CalcMarginBalance(price float64) {
 	if p.Amount == 0 {
 		return
@@ -121,40 +218,46 @@ func (p *Position) CalcMarginBalance(price float64) {
 	p.MarginBalance = p.Margin + p.UnrealisedPnl
 }
 
-func (p *Position) CalcLiquidationPrice(balance float64) {
+// ADLScore orders the position in the auto-deleveraging queue, it is the pnl ratio times the leverage
+// of a profitable position and 0 for the others
+func (p *Position) ADLScore() float64 {
+	if p.UnrealisedPnl <= 0 || p.Margin == 0 {
+		return 0
+	}
+
+	return p.UnrealisedPnl / p.Margin * p.Leverage.ToFloat64()
+}
+
+// CalcMaintMargin sets the maintenance margin of the position at the mark price
+func (p *Position) CalcMaintMargin(brackets MarginBrackets) {
+	notional := math.Abs(p.Amount) * p.MarkPrice
+
+	p.MaintMargin = brackets.Find(notional).MaintMargin(notional)
+}
+
+// CalcLiquidationPrice sets the mark price at which the margin balance falls to the maintenance margin,
+// isolated positions are backed by their own margin and cross positions by the balance the shared margin leaves them
+func (p *Position) CalcLiquidationPrice(balance float64, brackets MarginBrackets) {
 	if p.Amount == 0 {
 		return
 	}
 
-	leverage := float64(p.Leverage)
-
 	if p.MarginType == MarginTypeIsolated {
-		if p.Mode == PositionModeHedge {
-			if p.Side == PositionSideLong {
-				p.LiquidationPrice = p.Price * (1 - 1/leverage)
-			} else {
-				p.LiquidationPrice = p.Price * (1 + 1/leverage)
-			}
-		} else {
-			if p.Amount > 0 {
-				p.LiquidationPrice = p.Price * (1 - 1/leverage)
-			} else {
-				p.LiquidationPrice = p.Price * (1 + 1/leverage)
-			}
-		}
-	} else {
-		if p.Mode == PositionModeHedge {
-			if p.Side == PositionSideLong {
-				p.LiquidationPrice = p.Price - (balance + (balance-p.Margin)*(1-p.Margin/balance)*leverage)
-			} else {
-				p.LiquidationPrice = p.Price + (balance + (balance-p.Margin)*(1-p.Margin/balance)*leverage)
-			}
-		} else {
-			if p.Amount > 0 {
-				p.LiquidationPrice = p.Price - (balance + (balance-p.Margin)*(1-p.Margin/balance)*leverage)
-			} else {
-				p.LiquidationPrice = p.Price + (balance + (balance-p.Margin)*(1-p.Margin/balance)*leverage)
-			}
-		}
+		balance = p.Margin
+	}
+
+	amount := math.Abs(p.Amount)
+	bracket := brackets.Find(amount * p.MarkPrice)
+
+	side := -1.0
+	if p.IsLong() {
+		side = 1.0
 	}
+
+	price := (balance + bracket.MaintAmount - side*amount*p.Price) / (amount*bracket.MMR - side*amount)
+	if price < 0 {
+		price = 0
+	}
+
+	p.LiquidationPrice = price
 }
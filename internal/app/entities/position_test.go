@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositionRealizedPnl(t *testing.T) {
+	cases := []struct {
+		position Position
+		amount   float64
+		price    float64
+		pnl      float64
+	}{
+		{Position{Mode: PositionModeOneway, Amount: 2, Price: 100}, 1, 110, 10},   // oneway long
+		{Position{Mode: PositionModeOneway, Amount: -2, Price: 100}, 1, 110, -10}, // oneway short
+		{Position{Mode: PositionModeHedge, Side: PositionSideLong, Amount: 2, Price: 100}, 2, 90, -20},
+		{Position{Mode: PositionModeHedge, Side: PositionSideShort, Amount: 2, Price: 100}, 2, 90, 20},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.pnl, c.position.RealizedPnl(c.amount, c.price))
+	}
+}
+
+func TestPositionChargeMargin(t *testing.T) {
+	cases := []struct {
+		position Position
+		charged  bool
+	}{
+		{Position{Mode: PositionModeOneway, Amount: 2, Price: 100, Leverage: 10}, true},
+		{Position{Mode: PositionModeOneway, Amount: -2, Price: 100, Leverage: 10}, true},
+		{Position{Mode: PositionModeHedge, Side: PositionSideShort, Amount: 2, Price: 100, Leverage: 1}, true},
+		{Position{Mode: PositionModeOneway, Amount: 2, Price: 100, Leverage: 1}, false}, // long without leverage
+		{Position{Mode: PositionModeOneway, Price: 100, Leverage: 10}, false},           // closed
+	}
+
+	for _, c := range cases {
+		position := c.position
+		position.Margin = math.Abs(position.Amount) * position.Price / position.Leverage.ToFloat64()
+
+		// margin returned with the pnl when the position is closed at its entry price
+		closed := func(p Position) float64 {
+			return p.Margin + p.RealizedPnl(math.Abs(p.Amount), c.position.Price)
+		}
+		before := closed(position)
+
+		assert.Equal(t, c.charged, position.ChargeMargin(1))
+		if c.charged {
+			assert.InDelta(t, before-1, closed(position), 1e-9)
+		}
+	}
+}
+
+func TestPositionAddEntry(t *testing.T) {
+	position := Position{Amount: -1, Price: 100}
+
+	position.AddEntry(3, 120)
+	assert.Equal(t, float64(115), position.Price)
+
+	position = Position{}
+	position.AddEntry(2, 50)
+	assert.Equal(t, float64(50), position.Price)
+}
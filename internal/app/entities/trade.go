@@ -0,0 +1,51 @@
+package entities
+
+import "github.com/google/uuid"
+
+// Trade is a single fill of an order
+type Trade struct {
+	AccountUID   AccountUID   `json:"account_uid" db:"account_uid"`
+	TradeUID     string       `json:"trade_uid" db:"trade_uid"`
+	OrderUID     string       `json:"order_uid" db:"order_uid"`
+	Exchange     Exchange     `json:"exchange" db:"exchange"`
+	Symbol       Symbol       `json:"symbol" db:"symbol"`
+	Side         OrderSide    `json:"side" db:"side"`
+	PositionSide PositionSide `json:"position_side" db:"position_side"`
+	Price        float64      `json:"price" db:"price"`
+	Amount       float64      `json:"amount" db:"amount"`
+	Fee          float64      `json:"fee" db:"fee"`
+	FeeCoin      Coin         `json:"fee_coin" db:"fee_coin"`
+	IsMaker      bool         `json:"is_maker" db:"is_maker"`
+	CreateTS     int64        `json:"create_ts" db:"create_ts"`
+}
+
+func NewTrade(order *Order, amount, price float64, isMaker bool) *Trade {
+	return &Trade{
+		AccountUID:   order.AccountUID,
+		TradeUID:     uuid.New().String(),
+		OrderUID:     order.OrderUID,
+		Exchange:     order.Exchange,
+		Symbol:       order.Symbol,
+		Side:         order.Side,
+		PositionSide: order.PositionSide,
+		Price:        price,
+		Amount:       amount,
+		IsMaker:      isMaker,
+		CreateTS:     TS(),
+	}
+}
+
+func (t *Trade) FeeType() FeeType {
+	if t.IsMaker {
+		return FeeTypeMaker
+	}
+	return FeeTypeTaker
+}
+
+type TradeFilter struct {
+	OrderUID string `db:"order_uid"`
+	Symbol   string `db:"symbol"`
+	From     int64  `db:"from"`
+	To       int64  `db:"to"`
+	Limit    int64  `db:"limit"`
+}
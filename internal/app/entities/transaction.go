@@ -9,7 +9,10 @@ type Transaction struct {
 	Symbol          Symbol          `json:"symbol" db:"symbol"`
 	TransactionType TransactionType `json:"transaction_type" db:"transaction_type"`
 	Amount          float64         `json:"amount" db:"amount"`
+	Price           float64         `json:"price,omitempty" db:"price"`
 	CreateTS        int64           `json:"create_ts" db:"create_ts"`
+	FeeType         FeeType         `json:"fee_type,omitempty" db:"fee_type"`
+	OrderUID        string          `json:"order_uid,omitempty" db:"order_uid"`
 	// TradeUID      string     `json:"trade_uid" db:"trade_uid"`
 }
 
@@ -28,9 +31,15 @@ func NewTransaction(accountUID AccountUID, exchange Exchange, symbol Symbol, tra
 type TransactionType string
 
 const (
-	TransactionTypeLiquidation TransactionType = "liquidation"
-	TransactionTypeComission   TransactionType = "comission"
-	TransactionTypeRealizedPnl TransactionType = "realized pnl"
+	TransactionTypeLiquidation    TransactionType = "liquidation"
+	TransactionTypeLiquidationFee TransactionType = "liquidation fee"
+	TransactionTypeComission      TransactionType = "comission"
+	TransactionTypeRealizedPnl    TransactionType = "realized pnl"
+	TransactionTypeFunding        TransactionType = "funding"
+	TransactionTypeADL            TransactionType = "auto-deleveraging"
+	TransactionTypeTransferIn     TransactionType = "transfer_in"
+	TransactionTypeTransferOut    TransactionType = "transfer_out"
+	TransactionTypeCollateral     TransactionType = "collateral conversion"
 )
 
 type TransactionFilter struct {
@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"DemoExchange/internal/app/pkg/hash"
+)
+
+// ListenKeyTTL is the lifetime of a listen key without a keepalive
+const ListenKeyTTL = 60 * time.Minute
+
+// ListenKey authenticates the user data stream of the account
+type ListenKey struct {
+	Key        string     `json:"listen_key"`
+	AccountUID AccountUID `json:"-"`
+	ExpireTS   int64      `json:"expire_ts"`
+}
+
+func NewListenKey(accountUID AccountUID) *ListenKey {
+	return &ListenKey{
+		Key:        hash.GenSHA1(uuid.NewString()),
+		AccountUID: accountUID,
+		ExpireTS:   TS() + ListenKeyTTL.Milliseconds(),
+	}
+}
+
+func (k *ListenKey) IsExpired() bool {
+	return TS() > k.ExpireTS
+}
+
+type UserEventType string
+
+const (
+	UserEventSnapshot  UserEventType = "snapshot"
+	UserEventOrder     UserEventType = "order"
+	UserEventPosition  UserEventType = "position"
+	UserEventBalance   UserEventType = "balance"
+	UserEventKeyExpiry UserEventType = "listen_key_expired"
+)
+
+// UserEvent is a message of the user data stream
+type UserEvent struct {
+	Event    UserEventType `json:"event"`
+	Exchange Exchange      `json:"exchange,omitempty"`
+	Data     any           `json:"data,omitempty"`
+	TS       int64         `json:"ts"`
+}
+
+func NewUserEvent(event UserEventType, exchange Exchange, data any) *UserEvent {
+	return &UserEvent{
+		Event:    event,
+		Exchange: exchange,
+		Data:     data,
+		TS:       TS(),
+	}
+}
+
+// UserSnapshot is the state of the account sent when the stream is opened
+type UserSnapshot struct {
+	Orders    []*Order              `json:"orders"`
+	Positions []*Position           `json:"positions"`
+	Balances  map[Exchange]Balances `json:"balances"`
+}
@@ -20,6 +20,53 @@ type Balance struct {
 	InitialMargin    float64 `json:"initial_margin" db:"-"`
 	MaintMargin      float64 `json:"maint_margin" db:"-"`
 	UnrealisedPnl    float64 `json:"unrealised_pnl" db:"-"`
+	// CollateralValue is the discounted value of a collateral coin in the margin coin,
+	// for the margin coin it is the value of all collateral coins of the wallet
+	CollateralValue float64 `json:"collateral_value" db:"-"`
 }
 
 type Balances map[Coin]Balance
+
+// Add sums the balances coin by coin into b
+func (b Balances) Add(balances Balances) {
+	for coin, balance := range balances {
+		total := b[coin]
+		total.Coin = coin
+		total.Total += balance.Total
+		total.Hold += balance.Hold
+		total.AvailableBalance += balance.AvailableBalance
+		total.WalletBalance += balance.WalletBalance
+		total.MarginBalance += balance.MarginBalance
+		total.InitialMargin += balance.InitialMargin
+		total.MaintMargin += balance.MaintMargin
+		total.UnrealisedPnl += balance.UnrealisedPnl
+		total.CollateralValue += balance.CollateralValue
+		b[coin] = total
+	}
+}
+
+// AccountBalances is the balance view of a master account together with its sub-accounts
+type AccountBalances struct {
+	Total    Balances                `json:"total"`
+	Accounts map[AccountUID]Balances `json:"accounts"`
+}
+
+// WalletLimits are the balances credited to a new account by exchange and the deposit caps by coin,
+// a coin without a cap can not be deposited
+type WalletLimits struct {
+	Initial map[Exchange]map[Coin]float64
+	Max     map[Coin]float64
+}
+
+// Override returns the limits with the parts set in the service limits replaced
+func (l WalletLimits) Override(service WalletLimits) WalletLimits {
+	if len(service.Initial) > 0 {
+		l.Initial = service.Initial
+	}
+
+	if len(service.Max) > 0 {
+		l.Max = service.Max
+	}
+
+	return l
+}
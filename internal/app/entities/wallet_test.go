@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletLimitsOverride(t *testing.T) {
+	limits := WalletLimits{
+		Initial: map[Exchange]map[Coin]float64{ExchangeSpot: {"USDT": 3000}},
+		Max:     map[Coin]float64{"USDT": 5000},
+	}
+
+	assert.Equal(t, limits, limits.Override(WalletLimits{}))
+
+	service := limits.Override(WalletLimits{Max: map[Coin]float64{"USDT": 50000, "BTC": 1}})
+	assert.Equal(t, limits.Initial, service.Initial)
+	assert.Equal(t, 50000.0, service.Max["USDT"])
+	assert.Equal(t, 1.0, service.Max["BTC"])
+}
+
+func TestBalancesAdd(t *testing.T) {
+	total := make(Balances)
+	total.Add(Balances{"USDT": {Coin: "USDT", Total: 100, Hold: 10}})
+	total.Add(Balances{"USDT": {Coin: "USDT", Total: 50}, "BTC": {Coin: "BTC", Total: 1}})
+
+	assert.Equal(t, Balance{Coin: "USDT", Total: 150, Hold: 10}, total["USDT"])
+	assert.Equal(t, Balance{Coin: "BTC", Total: 1}, total["BTC"])
+}
@@ -0,0 +1,294 @@
+package marketstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"DemoExchange/internal/app/entities"
+	"DemoExchange/internal/app/markets"
+	"DemoExchange/internal/app/orderbook"
+	"DemoExchange/internal/app/tickers"
+)
+
+var (
+	ErrChannelIsNotValid  = errors.New("Channel is not valid")
+	ErrExchangeIsNotValid = errors.New("Exchange is not valid")
+	ErrSymbolIsNotValid   = errors.New("Symbol is not valid")
+	ErrTopicLimit         = errors.New("Topic limit exceeded")
+)
+
+const (
+	lenBufferClient = 100
+	topicLimit      = 50
+	orderbookLimit  = "20"
+	orderbookPeriod = time.Second
+)
+
+type Channel string
+
+const (
+	ChannelTicker    Channel = "ticker"
+	ChannelMarkPrice Channel = "mark_price"
+	ChannelOrderbook Channel = "orderbook"
+)
+
+func (c Channel) IsValid() bool {
+	switch c {
+	case ChannelTicker, ChannelMarkPrice, ChannelOrderbook:
+		return true
+	}
+	return false
+}
+
+// Topic is a channel of the exchange symbol
+type Topic struct {
+	Channel  Channel           `json:"channel"`
+	Exchange entities.Exchange `json:"exchange"`
+	Symbol   string            `json:"symbol"`
+}
+
+type Message struct {
+	Topic
+	Data any   `json:"data"`
+	TS   int64 `json:"ts"`
+}
+
+type MarkPrice struct {
+	PriceMark            float64 `json:"price_mark"`
+	PriceIndex           float64 `json:"price_index"`
+	LastFundingRate      float64 `json:"last_funding"`
+	NextFundingTimestamp int64   `json:"next_funding_timestamp"`
+}
+
+type Tickers interface {
+	Subscribe() (<-chan tickers.Update, func())
+}
+
+type Markets interface {
+	GetMarkets(exchange string) (markets.Markets, error)
+}
+
+type Orderbook interface {
+	GetOrderbook(ctx context.Context, exchange, symbol, limit string) (*orderbook.Orderbook, error)
+}
+
+type Logger interface {
+	Info(args ...interface{})
+}
+
+// Client is a connection receiving the messages of its topics
+type Client struct {
+	ch     chan *Message
+	topics map[Topic]struct{}
+}
+
+func (c *Client) Messages() <-chan *Message {
+	return c.ch
+}
+
+// Service fans out the tickers refreshes and the polled orderbooks to the subscribed clients
+type Service struct {
+	tickers   Tickers
+	markets   Markets
+	orderbook Orderbook
+	log       Logger
+
+	ctx     context.Context
+	mu      sync.RWMutex
+	clients map[Topic]map[*Client]struct{}
+	pollers map[Topic]context.CancelFunc
+}
+
+func New(tickers Tickers, markets Markets, orderbook Orderbook, log Logger) *Service {
+	return &Service{
+		tickers:   tickers,
+		markets:   markets,
+		orderbook: orderbook,
+		log:       log,
+		ctx:       context.Background(),
+		clients:   make(map[Topic]map[*Client]struct{}),
+		pollers:   make(map[Topic]context.CancelFunc),
+	}
+}
+
+func (s *Service) NewClient() *Client {
+	return &Client{
+		ch:     make(chan *Message, lenBufferClient),
+		topics: make(map[Topic]struct{}),
+	}
+}
+
+func (s *Service) Subscribe(c *Client, topic Topic) error {
+	if !topic.Channel.IsValid() {
+		return ErrChannelIsNotValid
+	}
+
+	if !topic.Exchange.IsValid() {
+		return ErrExchangeIsNotValid
+	}
+
+	// an unknown symbol would keep a poller requesting an orderbook that does not exist
+	exchangeMarkets, err := s.markets.GetMarkets(topic.Exchange.Name())
+	if err != nil {
+		return err
+	}
+
+	if _, ok := exchangeMarkets[topic.Symbol]; !ok {
+		return ErrSymbolIsNotValid
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := c.topics[topic]; ok {
+		return nil
+	}
+
+	if len(c.topics) >= topicLimit {
+		return ErrTopicLimit
+	}
+
+	c.topics[topic] = struct{}{}
+
+	if _, ok := s.clients[topic]; !ok {
+		s.clients[topic] = make(map[*Client]struct{})
+	}
+	s.clients[topic][c] = struct{}{}
+
+	if topic.Channel == ChannelOrderbook {
+		if _, ok := s.pollers[topic]; !ok {
+			ctx, cancel := context.WithCancel(s.ctx)
+			s.pollers[topic] = cancel
+			go s.pollOrderbook(ctx, topic)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) Unsubscribe(c *Client, topic Topic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unsubscribe(c, topic)
+}
+
+// Close unsubscribes the client from all its topics
+func (s *Service) Close(c *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for topic := range c.topics {
+		s.unsubscribe(c, topic)
+	}
+}
+
+func (s *Service) unsubscribe(c *Client, topic Topic) {
+	delete(c.topics, topic)
+	delete(s.clients[topic], c)
+
+	if len(s.clients[topic]) > 0 {
+		return
+	}
+
+	delete(s.clients, topic)
+
+	if cancel, ok := s.pollers[topic]; ok {
+		cancel()
+		delete(s.pollers, topic)
+	}
+}
+
+func (s *Service) publish(topic Topic, data any) {
+	message := &Message{
+		Topic: topic,
+		Data:  data,
+		TS:    entities.TS(),
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for c := range s.clients[topic] {
+		select {
+		case c.ch <- message:
+		default:
+		}
+	}
+}
+
+// Process publishes the tickers and the mark prices of every refresh until the context is done
+func (s *Service) Process(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	updates, unsubscribe := s.tickers.Subscribe()
+	defer unsubscribe()
+
+	s.log.Info("marketstream:Process Start")
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("marketstream:Process Stop")
+			return
+
+		case update := <-updates:
+			s.mu.RLock()
+			topics := make([]Topic, 0, len(s.clients))
+			for topic := range s.clients {
+				if topic.Channel != ChannelOrderbook && topic.Exchange.Name() == update.Exchange {
+					topics = append(topics, topic)
+				}
+			}
+			s.mu.RUnlock()
+
+			for _, topic := range topics {
+				ticker, ok := update.Tickers[topic.Symbol]
+				if !ok {
+					continue
+				}
+
+				if topic.Channel == ChannelMarkPrice {
+					s.publish(topic, MarkPrice{
+						PriceMark:            ticker.PriceMark,
+						PriceIndex:           ticker.PriceIndex,
+						LastFundingRate:      ticker.LastFundingRate,
+						NextFundingTimestamp: ticker.NextFundingTimestamp,
+					})
+					continue
+				}
+
+				s.publish(topic, ticker)
+			}
+		}
+	}
+}
+
+// pollOrderbook publishes the orderbook of the topic until the last client unsubscribes
+func (s *Service) pollOrderbook(ctx context.Context, topic Topic) {
+	s.log.Info(fmt.Sprintf("marketstream:pollOrderbook Start %+v", topic))
+
+	ticker := time.NewTicker(orderbookPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info(fmt.Sprintf("marketstream:pollOrderbook Stop %+v", topic))
+			return
+
+		case <-ticker.C:
+			book, err := s.orderbook.GetOrderbook(ctx, topic.Exchange.Name(), topic.Symbol, orderbookLimit)
+			if err != nil {
+				continue
+			}
+
+			s.publish(topic, book)
+		}
+	}
+}
@@ -1,7 +1,73 @@
 package orderbook
 
+import (
+	"encoding/json"
+	"errors"
+)
+
+var ErrLevelIsNotValid = errors.New("Orderbook level is not valid")
+
 type Orderbook struct {
-	Asks      interface{} `json:"asks" mapstructure:"asks"`
-	Bids      interface{} `json:"bids" mapstructure:"bids"`
-	Timestamp int64       `json:"timestamp" mapstructure:"timestamp"`
+	Asks      Levels `json:"asks" mapstructure:"asks"`
+	Bids      Levels `json:"bids" mapstructure:"bids"`
+	Timestamp int64  `json:"timestamp" mapstructure:"timestamp"`
+}
+
+// Level is a price level of the orderbook, encoded as [price, amount]
+type Level struct {
+	Price  float64
+	Amount float64
+}
+
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var values []json.Number
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	if len(values) < 2 {
+		return ErrLevelIsNotValid
+	}
+
+	price, err := values[0].Float64()
+	if err != nil {
+		return err
+	}
+
+	amount, err := values[1].Float64()
+	if err != nil {
+		return err
+	}
+
+	l.Price = price
+	l.Amount = amount
+
+	return nil
+}
+
+func (l Level) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]float64{l.Price, l.Amount})
+}
+
+type Levels []Level
+
+// Fill walks the levels while accept allows the price and returns the filled amount and its average price
+func (l Levels) Fill(amount float64, accept func(price float64) bool) (filled float64, avg float64) {
+	var cost float64
+
+	for _, level := range l {
+		if filled >= amount || !accept(level.Price) {
+			break
+		}
+
+		take := min(level.Amount, amount-filled)
+		filled += take
+		cost += take * level.Price
+	}
+
+	if filled > 0 {
+		avg = cost / filled
+	}
+
+	return filled, avg
 }
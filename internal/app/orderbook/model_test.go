@@ -0,0 +1,51 @@
+package orderbook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelsUnmarshal(t *testing.T) {
+	var levels Levels
+
+	err := json.Unmarshal([]byte(`[[100.5, 2], ["101", "0.5"]]`), &levels)
+	assert.NoError(t, err)
+	assert.Equal(t, Levels{{100.5, 2}, {101, 0.5}}, levels)
+
+	data, err := json.Marshal(levels)
+	assert.NoError(t, err)
+	assert.Equal(t, `[[100.5,2],[101,0.5]]`, string(data))
+
+	err = json.Unmarshal([]byte(`[[100.5]]`), &levels)
+	assert.ErrorIs(t, err, ErrLevelIsNotValid)
+}
+
+func TestLevelsFill(t *testing.T) {
+	asks := Levels{{100, 1}, {101, 1}, {102, 1}}
+
+	cases := []struct {
+		amount float64
+		limit  float64
+		filled float64
+		avg    float64
+	}{
+		{0.5, 100, 0.5, 100}, // filled by the first level
+		{2, 101, 2, 100.5},   // filled by two levels
+		{3, 101, 2, 100.5},   // stopped by the limit price
+		{5, 105, 3, 101},     // book is too thin
+		{1, 99, 0, 0},        // nothing to fill
+	}
+
+	for _, c := range cases {
+		filled, avg := asks.Fill(c.amount, func(price float64) bool { return price <= c.limit })
+		assert.Equal(t, c.filled, filled)
+		assert.InDelta(t, c.avg, avg, 1e-9)
+	}
+
+	// an empty side of the book fills nothing
+	filled, avg := Levels{}.Fill(1, func(float64) bool { return true })
+	assert.Equal(t, 0.0, filled)
+	assert.Equal(t, 0.0, avg)
+}
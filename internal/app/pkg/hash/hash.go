@@ -1,7 +1,10 @@
 package hash
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 )
 
@@ -10,3 +13,34 @@ func GenSHA1(in string) string {
 	h.Write([]byte(in))
 	return hex.EncodeToString(h.Sum(nil))
 }
+
+// GenHmacSHA256 returns the hex encoded HMAC-SHA256 of the message keyed by the secret
+func GenHmacSHA256(secret, message string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(message))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CheckHmacSHA256 compares the signature with the HMAC-SHA256 of the message in constant time
+func CheckHmacSHA256(secret, message, signature string) bool {
+	expected, err := hex.DecodeString(GenHmacSHA256(secret, message))
+	if err != nil {
+		return false
+	}
+
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(expected, actual)
+}
+
+// GenRandom returns n random bytes hex encoded
+func GenRandom(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,6 +1,7 @@
 package hash
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,3 +22,42 @@ func TestGenSHA1(t *testing.T) {
 		assert.Equal(t, c.output, result)
 	}
 }
+
+func TestGenHmacSHA256(t *testing.T) {
+	secret := "NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j"
+	message := "symbol=LTCBTC&side=BUY&type=LIMIT&timeInForce=GTC&quantity=1&price=0.1&recvWindow=5000&timestamp=1499827319559"
+
+	assert.Equal(t, "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71", GenHmacSHA256(secret, message))
+}
+
+func TestCheckHmacSHA256(t *testing.T) {
+	signature := GenHmacSHA256("secret", "timestamp=1")
+
+	cases := []struct {
+		secret    string
+		message   string
+		signature string
+		valid     bool
+	}{
+		{"secret", "timestamp=1", signature, true},                     // valid signature
+		{"secret", "timestamp=1", strings.ToUpper(signature), true},    // hex case does not matter
+		{"secret", "timestamp=2", signature, false},                    // changed message
+		{"other", "timestamp=1", signature, false},                     // wrong secret
+		{"secret", "timestamp=1", "not a signature", false},            // not hex
+		{"secret", "timestamp=1", signature[:len(signature)-2], false}, // truncated
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.valid, CheckHmacSHA256(c.secret, c.message, c.signature))
+	}
+}
+
+func TestGenRandom(t *testing.T) {
+	first, err := GenRandom(32)
+	assert.NoError(t, err)
+	assert.Len(t, first, 64)
+
+	second, err := GenRandom(32)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+}
@@ -26,3 +26,9 @@ type Ticker struct {
 }
 
 type Tickers map[string]Ticker
+
+// Update is the refreshed tickers of the exchange
+type Update struct {
+	Exchange string
+	Tickers  Tickers
+}
@@ -75,6 +75,7 @@ func (s *Service) Process(ctx context.Context) <-chan struct{} {
 					}
 
 					tickersMap.Store(exchange, tickers)
+					publish(exchange, tickers)
 
 					once.Do(func() {
 						wg.Done()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -13,6 +14,11 @@ var (
 	ErrTickerNotValid = errors.New("Ticker not valid")
 )
 
+var subscribers = struct {
+	sync.RWMutex
+	chs map[chan Update]struct{}
+}{chs: make(map[chan Update]struct{})}
+
 type Receiver struct{}
 
 func New() *Receiver {
@@ -74,3 +80,36 @@ func (r *Receiver) GetTickerWithContext(ctx context.Context, exchange, market st
 		}
 	}
 }
+
+// Subscribe returns the channel of the tickers refreshes and the function closing it,
+// a subscriber that is not ready misses the refresh
+func (r *Receiver) Subscribe() (<-chan Update, func()) {
+	ch := make(chan Update, len(exchanges))
+
+	subscribers.Lock()
+	subscribers.chs[ch] = struct{}{}
+	subscribers.Unlock()
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			subscribers.Lock()
+			delete(subscribers.chs, ch)
+			close(ch)
+			subscribers.Unlock()
+		})
+	}
+}
+
+func publish(exchange string, tickers Tickers) {
+	subscribers.RLock()
+	defer subscribers.RUnlock()
+
+	for ch := range subscribers.chs {
+		select {
+		case ch <- Update{exchange, tickers}:
+		default:
+		}
+	}
+}
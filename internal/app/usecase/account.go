@@ -3,6 +3,8 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"DemoExchange/internal/app/apperror"
 	"DemoExchange/internal/app/entities"
@@ -64,3 +66,141 @@ func (uc *Usecase) SetAccountPositionMode(ctx context.Context, exchange entities
 		return nil
 	})
 }
+
+const (
+	resetTimeout      = 30 * time.Second
+	resetRetryTimeout = time.Second
+)
+
+// ResetAccount cancels the orders and drops the positions of the account, archives its history
+// and restores the initial balances on every exchange
+func (uc *Usecase) ResetAccount(ctx context.Context, accountUID entities.AccountUID) error {
+	account, err := uc.account.SelectAccountByUID(ctx, accountUID)
+	if err != nil {
+		return err
+	}
+
+	// the new orders of the account are rejected until the reset is over
+	muOrder.Lock()
+	if _, ok := resettingAccounts[accountUID]; ok {
+		muOrder.Unlock()
+		return apperror.ErrAccountResetting
+	}
+	resettingAccounts[accountUID] = struct{}{}
+	muOrder.Unlock()
+
+	defer func() {
+		muOrder.Lock()
+		delete(resettingAccounts, accountUID)
+		muOrder.Unlock()
+	}()
+
+	if err := uc.cancelAccountOrders(ctx, accountUID); err != nil {
+		uc.log.Error(fmt.Sprintf("ResetAccount:cancelAccountOrders [account_uid: %s] error: %v", accountUID, err))
+		return err
+	}
+
+	muPositionProcess.Lock()
+	defer muPositionProcess.Unlock()
+
+	muWallet.Lock()
+	defer muWallet.Unlock()
+
+	if err := uc.account.WithTx(ctx, func(ctx context.Context) error {
+		archiveTS := entities.TS()
+
+		if err := uc.order.ArchiveAccountOrders(ctx, accountUID, archiveTS); err != nil {
+			uc.log.Error(fmt.Sprintf("ResetAccount:ArchiveAccountOrders [account_uid: %s] error: %v", accountUID, err))
+			return err
+		}
+
+		if err := uc.trade.ArchiveAccountTrades(ctx, accountUID, archiveTS); err != nil {
+			uc.log.Error(fmt.Sprintf("ResetAccount:ArchiveAccountTrades [account_uid: %s] error: %v", accountUID, err))
+			return err
+		}
+
+		if err := uc.transaction.ArchiveAccountTransactions(ctx, accountUID, archiveTS); err != nil {
+			uc.log.Error(fmt.Sprintf("ResetAccount:ArchiveAccountTransactions [account_uid: %s] error: %v", accountUID, err))
+			return err
+		}
+
+		if err := uc.position.DeleteAccountPositions(ctx, accountUID); err != nil {
+			uc.log.Error(fmt.Sprintf("ResetAccount:DeleteAccountPositions [account_uid: %s] error: %v", accountUID, err))
+			return err
+		}
+
+		if err := uc.wallet.DeleteAccountWallets(ctx, accountUID); err != nil {
+			uc.log.Error(fmt.Sprintf("ResetAccount:DeleteAccountWallets [account_uid: %s] error: %v", accountUID, err))
+			return err
+		}
+
+		// sub-accounts are funded by the master
+		if account.IsSub() {
+			return nil
+		}
+
+		return uc.appendInitialBalances(ctx, account.Service, accountUID)
+	}); err != nil {
+		return err
+	}
+
+	// the traded volume is archived with the orders
+	uc.feeTiers.delete(accountUID)
+
+	for _, exchange := range exchanges {
+		uc.notifyBalance(exchange, accountUID)
+	}
+
+	// the watchers stop on the next tick once the cached positions are empty
+	for _, position := range uc.cachePositions.List() {
+		if position.AccountUID == accountUID {
+			position.Amount = 0
+			position.HoldAmount = 0
+		}
+	}
+
+	uc.log.Info(fmt.Sprintf("ResetAccount: [account_uid: %s]", accountUID))
+
+	return nil
+}
+
+// cancelAccountOrders cancels the cached orders of the account and waits until their holds are released,
+// the orders stored but not processed yet are waited for until they are cached
+func (uc *Usecase) cancelAccountOrders(ctx context.Context, accountUID entities.AccountUID) error {
+	ctx, cancel := context.WithTimeout(ctx, resetTimeout)
+	defer cancel()
+
+	for {
+		pending := false
+		for _, exchange := range exchanges {
+			if err := uc.checkPresentPendingOrders(ctx, exchange, accountUID, nil); err != nil {
+				if !errors.Is(err, apperror.ErrOpenOrdersExists) {
+					return err
+				}
+				pending = true
+			}
+		}
+
+		for _, o := range uc.cacheOrders.List() {
+			if o.AccountUID != accountUID {
+				continue
+			}
+
+			pending = true
+			if !o.Status.IsFinal() {
+				o.Status = entities.OrderStatusCancelled
+				o.UpdateTS = entities.TS()
+			}
+		}
+
+		if !pending {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return apperror.ErrOpenOrdersExists
+		case <-time.After(resetRetryTimeout):
+		}
+	}
+}
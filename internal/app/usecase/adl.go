@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"DemoExchange/internal/app/entities"
+)
+
+// coverBankruptcy covers the loss of the liquidated amount of the position beyond its margin from the insurance fund,
+// the part the fund can't cover is taken from the opposite positions by auto-deleveraging
+func (uc *Usecase) coverBankruptcy(ctx context.Context, position *entities.Position, amount, deficit float64) error {
+	coins := position.Symbol.GetCoins()
+
+	balance, err := uc.insurance.SelectInsuranceFundBalance(ctx, position.Exchange, coins.CoinBase)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("coverBankruptcy:SelectInsuranceFundBalance [exchange: %s, coin: %s] error: %v", position.Exchange, coins.CoinBase, err))
+		return err
+	}
+
+	covered := math.Min(deficit, math.Max(balance, 0))
+	if covered > 0 {
+		fund := entities.NewInsuranceFund(position.AccountUID, position.Exchange, position.Symbol, coins.CoinBase, -covered)
+		if err := uc.insurance.InsertInsuranceFund(ctx, fund); err != nil {
+			uc.log.Error(fmt.Sprintf("coverBankruptcy:InsertInsuranceFund [%+v] error: %v", *fund, err))
+			return err
+		}
+	}
+
+	uc.log.Info(fmt.Sprintf("coverBankruptcy [%+v] deficit: %v, fund: %v, covered: %v", *position, deficit, balance, covered))
+
+	if deficit > covered {
+		return uc.autoDeleverage(ctx, position, amount, deficit-covered)
+	}
+
+	return nil
+}
+
+// autoDeleverage closes the amount of the bankrupt position against the opposite positions in the queue order,
+// every reduced position gives up its share of the shortfall out of its pnl
+func (uc *Usecase) autoDeleverage(ctx context.Context, position *entities.Position, amount, shortfall float64) error {
+	queue, err := uc.adlQueue(ctx, position)
+	if err != nil {
+		return err
+	}
+
+	coins := position.Symbol.GetCoins()
+	left := amount
+
+	for _, p := range queue {
+		if left <= 0 {
+			break
+		}
+
+		positionAmount := math.Abs(p.Amount)
+		closeAmount := math.Min(left, positionAmount)
+		left -= closeAmount
+
+		share := closeAmount / positionAmount
+		pnl := p.UnrealisedPnl * share
+		margin := p.Margin * share
+		loss := math.Min(shortfall*closeAmount/amount, pnl)
+
+		p.Amount = math.Copysign(positionAmount-closeAmount, p.Amount)
+		p.Margin -= margin
+		if p.HoldAmount > positionAmount-closeAmount {
+			p.HoldAmount = positionAmount - closeAmount
+		}
+
+		if err := uc.updatePosition(ctx, p); err != nil {
+			uc.log.Error(fmt.Sprintf("autoDeleverage:updatePosition [%+v] error: %v", *p, err))
+			return err
+		}
+
+		p.CalcMarginBalance(p.MarkPrice)
+
+		uc.log.Info(fmt.Sprintf("autoDeleverage [%+v] amount: %v, pnl: %v, loss: %v", *p, closeAmount, pnl, loss))
+
+		balance := entities.Balance{
+			Coin:  coins.CoinBase,
+			Total: margin + pnl - loss,
+		}
+		if err := uc.AppendBalance(ctx, p.Exchange, p.AccountUID, balance); err != nil {
+			return err
+		}
+
+		transaction := entities.NewTransaction(p.AccountUID, p.Exchange, p.Symbol, entities.TransactionTypeADL, pnl-loss)
+		transaction.Price = p.MarkPrice
+		if err := uc.AppendTransaction(ctx, transaction); err != nil {
+			uc.log.Error(fmt.Sprintf("autoDeleverage:AppendTransaction [%+v] error: %v", *transaction, err))
+			return err
+		}
+	}
+
+	if left > 0 {
+		uc.log.Error(fmt.Sprintf("autoDeleverage [%+v] queue is exhausted, amount left: %v", *position, left))
+	}
+
+	return nil
+}
+
+// adlQueue returns the profitable positions opposite to the position in the order they are deleveraged
+func (uc *Usecase) adlQueue(ctx context.Context, position *entities.Position) ([]*entities.Position, error) {
+	positions, err := uc.position.SelectSymbolOpenPositions(ctx, position.Exchange, position.Symbol)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("adlQueue:SelectSymbolOpenPositions [exchange: %s, symbol: %s] error: %v", position.Exchange, position.Symbol, err))
+		return nil, err
+	}
+
+	queue := make([]*entities.Position, 0)
+
+	for _, p := range positions {
+		if p.IsLong() == position.IsLong() {
+			continue
+		}
+
+		if cached, ok := uc.cachePositions.Get(p.PositionUID); ok {
+			p = cached
+		}
+
+		p.CalcMarginBalance(position.MarkPrice)
+		if p.ADLScore() > 0 {
+			queue = append(queue, p)
+		}
+	}
+
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].ADLScore() > queue[j].ADLScore()
+	})
+
+	return queue, nil
+}
+
+// setADLRanks sets the rank of the open positions in the auto-deleveraging queues of their symbol and side,
+// only the symbols of the positions are read
+func (uc *Usecase) setADLRanks(ctx context.Context, exchange entities.Exchange, positions []*entities.Position) error {
+	exchangeTickers, err := uc.tickers.GetTickers(exchange.Name())
+	if err != nil {
+		return err
+	}
+
+	type key struct {
+		symbol entities.Symbol
+		long   bool
+	}
+
+	scores := make(map[key][]float64)
+	symbols := make(map[entities.Symbol]struct{})
+
+	for _, position := range positions {
+		if _, ok := symbols[position.Symbol]; ok || position.Amount == 0 {
+			continue
+		}
+		symbols[position.Symbol] = struct{}{}
+
+		ticker, ok := exchangeTickers[position.Symbol.String()]
+		if !ok {
+			continue
+		}
+
+		openPositions, err := uc.position.SelectSymbolOpenPositions(ctx, exchange, position.Symbol)
+		if err != nil {
+			uc.log.Error(fmt.Sprintf("setADLRanks:SelectSymbolOpenPositions [exchange: %s, symbol: %s] error: %v", exchange, position.Symbol, err))
+			return err
+		}
+
+		for _, p := range openPositions {
+			p.CalcMarginBalance(markPrice(ticker))
+			if score := p.ADLScore(); score > 0 {
+				k := key{symbol: p.Symbol, long: p.IsLong()}
+				scores[k] = append(scores[k], score)
+			}
+		}
+	}
+
+	for _, p := range positions {
+		if p.Amount != 0 {
+			p.ADLRank = entities.ADLRank(p.ADLScore(), scores[key{symbol: p.Symbol, long: p.IsLong()}])
+		}
+	}
+
+	return nil
+}
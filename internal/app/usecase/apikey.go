@@ -3,13 +3,18 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"DemoExchange/internal/app/apperror"
 	"DemoExchange/internal/app/entities"
 )
 
-func (uc *Usecase) CreateToken(ctx context.Context, service, userID string) (entities.Token, error) {
+// signaturesCleanInterval is how often the expired signatures are dropped from the replay cache
+const signaturesCleanInterval int64 = 1000
+
+func (uc *Usecase) CreateToken(ctx context.Context, service, userID string, settings entities.KeySettings) (*entities.Key, error) {
 	var key *entities.Key
 
 	if err := uc.apikey.WithTx(ctx, func(ctx context.Context) error {
@@ -19,58 +24,171 @@ func (uc *Usecase) CreateToken(ctx context.Context, service, userID string) (ent
 			return err
 		}
 
-		keys, err := uc.apikey.SelectAccountKeys(ctx, account.AccountUID)
+		key, err = uc.createAccountKey(ctx, account.AccountUID, settings)
 		if err != nil {
-			uc.log.Error(fmt.Sprintf("CreateToken:SelectAccountKeys [account_uid: %s] error: %v", account.AccountUID, err))
-			return err
-		}
-
-		if len(keys) >= uc.cfg.KeyLimit {
-			return apperror.ErrTokenLimitExceeded
-		}
-
-		key = entities.NewToken(account.AccountUID)
-
-		err = uc.apikey.InsertAccountKey(ctx, key)
-		if err != nil {
-			uc.log.Error(fmt.Sprintf("CreateToken:InsertAccountKey [%+v] error: %v", *key, err))
 			return err
 		}
 
 		if account.IsNew {
-			for _, exchange := range exchanges {
-				balance := uc.GetInitialBalance(exchange)
-
-				wallet := entities.Wallet{
-					Exchange:   exchange,
-					AccountUID: key.AccountUID,
-					Balance: entities.Balance{
-						Coin:  balance.Coin,
-						Total: balance.Total,
-					},
-					UpdateTS: entities.TS(),
-				}
-
-				err := uc.wallet.AppendTotalCoin(ctx, wallet)
-				if err != nil {
-					uc.log.Error(fmt.Sprintf("CreateToken:AppendTotalCoin [%+v] error: %v", wallet, err))
-					return err
-				}
+			if err := uc.appendInitialBalances(ctx, account.Service, account.AccountUID); err != nil {
+				uc.log.Error(fmt.Sprintf("CreateToken:appendInitialBalances [account_uid: %s] error: %v", account.AccountUID, err))
+				return err
 			}
 		}
 
 		return nil
 	}); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	uc.log.Info(fmt.Sprintf("CreateToken: [service: %s, user_id: %s]", service, userID))
 
-	return key.Token, nil
+	return key, nil
 }
 
-func (uc *Usecase) GetAccountUID(ctx context.Context, token entities.Token) (entities.AccountUID, error) {
-	return uc.apikey.SelectAccountUID(ctx, token)
+func (uc *Usecase) createAccountKey(ctx context.Context, accountUID entities.AccountUID, settings entities.KeySettings) (*entities.Key, error) {
+	keys, err := uc.apikey.SelectAccountKeys(ctx, accountUID)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("createAccountKey:SelectAccountKeys [account_uid: %s] error: %v", accountUID, err))
+		return nil, err
+	}
+
+	if len(keys) >= uc.cfg.KeyLimit {
+		return nil, apperror.ErrTokenLimitExceeded
+	}
+
+	key, err := entities.NewToken(accountUID)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("createAccountKey:NewToken [account_uid: %s] error: %v", accountUID, err))
+		return nil, err
+	}
+
+	if err := key.Apply(settings); err != nil {
+		return nil, err
+	}
+
+	err = uc.apikey.InsertAccountKey(ctx, key)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("createAccountKey:InsertAccountKey [account_uid: %s] [token: %s] error: %v", accountUID, key.Token.Masked(), err))
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// AuthenticateRequest checks the signature of the request with the key secret, the expiry and the whitelist of the key
+// and returns the key, a request is accepted once within its receive window.
+// An unsigned key is accepted without a signature until its secret is rotated or the grace period of the config ends
+func (uc *Usecase) AuthenticateRequest(ctx context.Context, req *entities.SignedRequest) (*entities.Key, error) {
+	ts := entities.TS()
+
+	key, err := uc.apikey.SelectAccountKey(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Disabled {
+		return nil, apperror.ErrTokenNotFound
+	}
+
+	if key.IsExpired(ts) {
+		return nil, apperror.ErrTokenExpired
+	}
+
+	if !key.AllowsIP(req.IP) {
+		return nil, apperror.ErrIPNotAllowed
+	}
+
+	if key.Unsigned && req.Signature == "" && ts < uc.cfg.UnsignedKeysUntil {
+		uc.log.Info(fmt.Sprintf("AuthenticateRequest: unsigned request [account_uid: %s] [token: %s]", key.AccountUID, key.Token.Masked()))
+		key.Secret = ""
+		return key, nil
+	}
+
+	if err := req.Validate(ts); err != nil {
+		return nil, err
+	}
+
+	if !req.Verify(key.Secret) {
+		return nil, apperror.ErrSignatureNotValid
+	}
+
+	// the hex signature is checked case insensitively, so a replay with the case changed has the same key
+	if !uc.signatures.add(string(req.Token)+":"+strings.ToLower(req.Signature), req.ExpireTS(), ts) {
+		return nil, apperror.ErrRequestReplayed
+	}
+
+	key.Secret = ""
+
+	return key, nil
+}
+
+// KeysList returns the active keys of the account without their secrets
+func (uc *Usecase) KeysList(ctx context.Context, service, userID string) ([]entities.Key, error) {
+	account, err := uc.account.SelectAccount(ctx, service, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.apikey.SelectAccountKeys(ctx, account.AccountUID)
+}
+
+// UpdateToken changes the label, the scopes, the expiry and the whitelist of the key
+func (uc *Usecase) UpdateToken(ctx context.Context, token entities.Token, settings entities.KeySettings) (*entities.Key, error) {
+	key, err := uc.apikey.SelectAccountKey(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Disabled {
+		return nil, apperror.ErrTokenNotFound
+	}
+
+	if err := key.Apply(settings); err != nil {
+		return nil, err
+	}
+
+	key.Secret = ""
+	key.UpdateTS = entities.TS()
+
+	if err := uc.apikey.UpdateAccountKeySettings(ctx, key); err != nil {
+		uc.log.Error(fmt.Sprintf("UpdateToken:UpdateAccountKeySettings [account_uid: %s] [token: %s] error: %v", key.AccountUID, token.Masked(), err))
+		return nil, err
+	}
+
+	uc.log.Info(fmt.Sprintf("UpdateToken: [%s]", token.Masked()))
+
+	return key, nil
+}
+
+// RotateTokenSecret issues a new secret for the key and returns it once, the key is no longer accepted unsigned
+func (uc *Usecase) RotateTokenSecret(ctx context.Context, token entities.Token) (*entities.Key, error) {
+	key, err := uc.apikey.SelectAccountKey(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Disabled {
+		return nil, apperror.ErrTokenNotFound
+	}
+
+	key.Secret, err = entities.NewSecret()
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("RotateTokenSecret:NewSecret [token: %s] error: %v", token.Masked(), err))
+		return nil, err
+	}
+
+	key.Unsigned = false
+	key.UpdateTS = entities.TS()
+
+	if err := uc.apikey.UpdateAccountKeySecret(ctx, key); err != nil {
+		uc.log.Error(fmt.Sprintf("RotateTokenSecret:UpdateAccountKeySecret [account_uid: %s] [token: %s] error: %v", key.AccountUID, token.Masked(), err))
+		return nil, err
+	}
+
+	uc.log.Info(fmt.Sprintf("RotateTokenSecret: [%s]", token.Masked()))
+
+	return key, nil
 }
 
 func (uc *Usecase) DisableToken(ctx context.Context, token entities.Token) error {
@@ -82,7 +200,43 @@ func (uc *Usecase) DisableToken(ctx context.Context, token entities.Token) error
 
 	err := uc.apikey.UpdateAccountKey(ctx, key)
 
-	uc.log.Info(fmt.Sprintf("DisableToken: [%s]", token))
+	uc.log.Info(fmt.Sprintf("DisableToken: [%s]", token.Masked()))
 
 	return err
 }
+
+// signatures remembers the accepted signatures until their requests expire to reject the replays
+type signatures struct {
+	mu      sync.Mutex
+	expires map[string]int64
+	cleanTS int64
+}
+
+func newSignatures() *signatures {
+	return &signatures{
+		expires: make(map[string]int64),
+	}
+}
+
+// add returns false when the signature is already accepted and not expired at ts
+func (s *signatures) add(signature string, expireTS, ts int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ts-s.cleanTS >= signaturesCleanInterval {
+		for k, expire := range s.expires {
+			if expire < ts {
+				delete(s.expires, k)
+			}
+		}
+		s.cleanTS = ts
+	}
+
+	if expire, ok := s.expires[signature]; ok && expire >= ts {
+		return false
+	}
+
+	s.expires[signature] = expireTS
+
+	return true
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/entities"
+	"DemoExchange/internal/app/tickers"
+)
+
+// collateralSymbol is the symbol pricing the collateral coin in the margin coin
+func collateralSymbol(coin, marginCoin entities.Coin) entities.Symbol {
+	return entities.Symbol(fmt.Sprintf("%s/%s", coin, marginCoin))
+}
+
+// isCollateral reports whether the coin of the futures wallet counts as margin for another coin
+func (uc *Usecase) isCollateral(coin entities.Coin) bool {
+	_, ok := uc.cfg.CollateralHaircuts[coin]
+	return ok
+}
+
+// collateralValues returns the discounted value in the margin coin of the available collateral coins of the balances
+func (uc *Usecase) collateralValues(exchangeTickers tickers.Tickers, balances entities.Balances, marginCoin entities.Coin) map[entities.Coin]float64 {
+	values := make(map[entities.Coin]float64)
+
+	for coin, balance := range balances {
+		if coin == marginCoin || !uc.isCollateral(coin) {
+			continue
+		}
+
+		ticker, ok := exchangeTickers[collateralSymbol(coin, marginCoin).String()]
+		if !ok || ticker.Last <= 0 {
+			continue
+		}
+
+		available := math.Max(balance.Total-balance.Hold, 0)
+		values[coin] = available * ticker.Last * (1 - uc.cfg.CollateralHaircuts[coin])
+	}
+
+	return values
+}
+
+// GetCollateral returns the value of the collateral coins of the account wallet available as margin in the coin
+func (uc *Usecase) GetCollateral(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin) (float64, error) {
+	if exchange != entities.ExchangeFutures || len(uc.cfg.CollateralHaircuts) == 0 {
+		return 0, nil
+	}
+
+	wallet := entities.Wallet{
+		Exchange:   exchange,
+		AccountUID: accountUID,
+	}
+	balances, err := uc.wallet.SelectBalances(ctx, wallet)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("GetCollateral:SelectBalances [%+v] error: %v", wallet, err))
+		return 0, err
+	}
+
+	exchangeTickers, err := uc.tickers.GetTickers(exchange.Name())
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("GetCollateral:GetTickers [exchange: %s] error: %v", exchange.Name(), err))
+		return 0, err
+	}
+
+	var collateral float64
+	for _, value := range uc.collateralValues(exchangeTickers, balances, coin) {
+		collateral += value
+	}
+
+	return collateral, nil
+}
+
+// availableBalance returns the amount of the coin free to leave the wallet, in the futures wallet
+// the collateral coins backing the hold of a margin coin above its total are kept
+func (uc *Usecase) availableBalance(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin) (float64, error) {
+	wallet := entities.Wallet{
+		Exchange:   exchange,
+		AccountUID: accountUID,
+	}
+	balances, err := uc.wallet.SelectBalances(ctx, wallet)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("availableBalance:SelectBalances [%+v] error: %v", wallet, err))
+		return 0, err
+	}
+
+	balance, ok := balances[coin]
+	if !ok {
+		return 0, apperror.ErrBalanceNotFound
+	}
+
+	available := math.Max(balance.Total-balance.Hold, 0)
+	if exchange != entities.ExchangeFutures || !uc.isCollateral(coin) || available == 0 {
+		return available, nil
+	}
+
+	exchangeTickers, err := uc.tickers.GetTickers(exchange.Name())
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("availableBalance:GetTickers [exchange: %s] error: %v", exchange.Name(), err))
+		return 0, err
+	}
+
+	for marginCoin, margin := range balances {
+		uncovered := margin.Hold - margin.Total
+		if uncovered <= 0 || uc.isCollateral(marginCoin) {
+			continue
+		}
+
+		// the other collateral coins back the hold first
+		for collateralCoin, value := range uc.collateralValues(exchangeTickers, balances, marginCoin) {
+			if collateralCoin != coin {
+				uncovered -= value
+			}
+		}
+
+		if uncovered <= 0 {
+			continue
+		}
+
+		ticker, ok := exchangeTickers[collateralSymbol(coin, marginCoin).String()]
+		if !ok {
+			return 0, nil
+		}
+
+		value := ticker.Last * (1 - uc.cfg.CollateralHaircuts[coin])
+		if value <= 0 {
+			continue
+		}
+
+		available -= uncovered / value
+	}
+
+	return math.Max(available, 0), nil
+}
+
+// ConvertCollateral sells the collateral coins of the account at the ticker price
+// until the amount of the margin coin is raised or the collateral is spent
+func (uc *Usecase) ConvertCollateral(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin, amount float64) (float64, error) {
+	if exchange != entities.ExchangeFutures || amount <= 0 {
+		return 0, nil
+	}
+
+	wallet := entities.Wallet{
+		Exchange:   exchange,
+		AccountUID: accountUID,
+	}
+	balances, err := uc.wallet.SelectBalances(ctx, wallet)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("ConvertCollateral:SelectBalances [%+v] error: %v", wallet, err))
+		return 0, err
+	}
+
+	exchangeTickers, err := uc.tickers.GetTickers(exchange.Name())
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("ConvertCollateral:GetTickers [exchange: %s] error: %v", exchange.Name(), err))
+		return 0, err
+	}
+
+	values := uc.collateralValues(exchangeTickers, balances, coin)
+
+	// the coins with the lowest haircut are sold first
+	coins := make([]entities.Coin, 0, len(values))
+	for collateralCoin := range values {
+		coins = append(coins, collateralCoin)
+	}
+	sort.Slice(coins, func(i, j int) bool {
+		hi, hj := uc.cfg.CollateralHaircuts[coins[i]], uc.cfg.CollateralHaircuts[coins[j]]
+		if hi != hj {
+			return hi < hj
+		}
+		return coins[i] < coins[j]
+	})
+
+	var converted float64
+
+	for _, collateralCoin := range coins {
+		if converted >= amount {
+			break
+		}
+
+		symbol := collateralSymbol(collateralCoin, coin)
+		price := exchangeTickers[symbol.String()].Last
+		balance := balances[collateralCoin]
+
+		sold := math.Min((amount-converted)/price, balance.Total-balance.Hold)
+		value := sold * price
+
+		if err := uc.SubtractBalance(ctx, exchange, accountUID, entities.Balance{Coin: collateralCoin, Total: sold}); err != nil {
+			return converted, err
+		}
+
+		if err := uc.AppendBalance(ctx, exchange, accountUID, entities.Balance{Coin: coin, Total: value}); err != nil {
+			return converted, err
+		}
+
+		transaction := entities.NewTransaction(accountUID, exchange, symbol, entities.TransactionTypeCollateral, value)
+		transaction.Price = price
+		if err := uc.AppendTransaction(ctx, transaction); err != nil {
+			uc.log.Error(fmt.Sprintf("ConvertCollateral:AppendTransaction [%+v] error: %v", *transaction, err))
+			return converted, err
+		}
+
+		converted += value
+	}
+
+	return converted, nil
+}
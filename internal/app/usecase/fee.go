@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/entities"
+)
+
+const (
+	feeVolumePeriod = 30 * 24 * time.Hour
+	// feeTierTTL is how long the tier upgraded from the traded volume is kept before the volume is summed again
+	feeTierTTL int64 = 60000
+)
+
+// SetAccountFeeTier pins the fee tier of the account, an empty tier unpins it
+func (uc *Usecase) SetAccountFeeTier(ctx context.Context, service, userID, tier string) (*entities.Account, error) {
+	if tier != "" {
+		if _, ok := uc.findFeeTier(tier); !ok {
+			return nil, apperror.ErrFeeTierNotFound
+		}
+	}
+
+	account, err := uc.account.SelectAccount(ctx, service, userID)
+	if err != nil {
+		if !errors.Is(err, apperror.ErrAccountNotFound) {
+			uc.log.Error(fmt.Sprintf("SetAccountFeeTier:SelectAccount [service: %s, user_id: %s] error: %v", service, userID, err))
+		}
+		return nil, err
+	}
+
+	account.FeeTier = tier
+	account.FeeTierPinned = tier != ""
+	account.UpdateTS = entities.TS()
+
+	if err := uc.account.UpdateFeeTier(ctx, account); err != nil {
+		uc.log.Error(fmt.Sprintf("SetAccountFeeTier:UpdateFeeTier [%+v] error: %v", *account, err))
+		return nil, err
+	}
+
+	uc.feeTiers.delete(account.AccountUID)
+
+	return account, nil
+}
+
+func (uc *Usecase) findFeeTier(name string) (entities.FeeTier, bool) {
+	for _, tier := range uc.cfg.FeeTiers {
+		if tier.Name == name {
+			return tier, true
+		}
+	}
+
+	return entities.FeeTier{}, false
+}
+
+// accountFeeTier returns the fee tier of the account,
+// a not pinned tier is upgraded from the traded volume when the auto upgrade is on
+func (uc *Usecase) accountFeeTier(ctx context.Context, account *entities.Account) (entities.FeeTier, bool) {
+	if len(uc.cfg.FeeTiers) == 0 {
+		return entities.FeeTier{}, false
+	}
+
+	if !account.FeeTierPinned && uc.cfg.FeeTierAutoUpgrade {
+		ts := entities.TS()
+		if tier, ok := uc.feeTiers.get(account.AccountUID, ts); ok {
+			return tier, true
+		}
+
+		volume, err := uc.order.SelectAccountVolume(ctx, account.AccountUID, ts-feeVolumePeriod.Milliseconds())
+		if err != nil {
+			uc.log.Error(fmt.Sprintf("accountFeeTier:SelectAccountVolume [AccountUID: %s] error: %v", account.AccountUID, err))
+		} else {
+			tier := uc.cfg.FeeTiers[0]
+			for _, t := range uc.cfg.FeeTiers {
+				if volume >= t.Volume && t.Volume >= tier.Volume {
+					tier = t
+				}
+			}
+
+			if tier.Name != account.FeeTier {
+				account.FeeTier = tier.Name
+				account.UpdateTS = entities.TS()
+
+				if err := uc.account.UpdateFeeTier(ctx, account); err != nil {
+					uc.log.Error(fmt.Sprintf("accountFeeTier:UpdateFeeTier [%+v] error: %v", *account, err))
+				}
+			}
+
+			uc.feeTiers.set(account.AccountUID, tier, ts+feeTierTTL)
+
+			return tier, true
+		}
+	}
+
+	if tier, ok := uc.findFeeTier(account.FeeTier); ok {
+		return tier, true
+	}
+
+	return uc.cfg.FeeTiers[0], true
+}
+
+// setOrderFees replaces the fees of the market data by the fee tier of the account
+func (uc *Usecase) setOrderFees(ctx context.Context, account *entities.Account, o *entities.Order) {
+	tier, ok := uc.accountFeeTier(ctx, account)
+	if !ok {
+		return
+	}
+
+	rates := tier.Rates(o.Exchange)
+	o.MakerFee = rates.Maker
+	o.TakerFee = rates.Taker
+}
+
+// feeTiers keeps the tiers upgraded from the traded volume of the accounts until they expire
+type feeTiers struct {
+	mu    sync.Mutex
+	tiers map[entities.AccountUID]cachedFeeTier
+}
+
+type cachedFeeTier struct {
+	tier     entities.FeeTier
+	expireTS int64
+}
+
+func newFeeTiers() *feeTiers {
+	return &feeTiers{
+		tiers: make(map[entities.AccountUID]cachedFeeTier),
+	}
+}
+
+// get returns the tier of the account when it is not expired at ts
+func (f *feeTiers) get(accountUID entities.AccountUID, ts int64) (entities.FeeTier, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cached, ok := f.tiers[accountUID]
+	if !ok {
+		return entities.FeeTier{}, false
+	}
+
+	if cached.expireTS < ts {
+		delete(f.tiers, accountUID)
+		return entities.FeeTier{}, false
+	}
+
+	return cached.tier, true
+}
+
+func (f *feeTiers) set(accountUID entities.AccountUID, tier entities.FeeTier, expireTS int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tiers[accountUID] = cachedFeeTier{
+		tier:     tier,
+		expireTS: expireTS,
+	}
+}
+
+func (f *feeTiers) delete(accountUID entities.AccountUID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.tiers, accountUID)
+}
@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/entities"
+)
+
+const (
+	fundingTimeout = 10 * time.Second
+	// fundingSaveInterval is how often the rate of the next funding time is stored
+	fundingSaveInterval int64 = 60000
+)
+
+// ProcessFunding settles open futures positions at every funding timestamp of their symbol.
+// The schedule is stored, so the funding times passed while the service was down are settled on start
+func (uc *Usecase) ProcessFunding(ctx context.Context) {
+	schedule := make(map[string]*entities.Funding)
+
+	fundings, err := uc.funding.SelectFundings(ctx)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("ProcessFunding:SelectFundings error: %v", err))
+	}
+
+	for _, funding := range fundings {
+		schedule[funding.Symbol.String()] = funding
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			uc.log.Error(fmt.Sprintf("ProcessFunding:Done error: %v", ctx.Err()))
+			return
+
+		case <-time.After(fundingTimeout):
+			ts := entities.TS()
+
+			for _, funding := range schedule {
+				for _, fundingTS := range funding.DueTimes(ts) {
+					if err := uc.settleFunding(ctx, funding, fundingTS); err != nil {
+						uc.log.Error(fmt.Sprintf("ProcessFunding:settleFunding [%+v] [funding_ts: %d] error: %v", *funding, fundingTS, err))
+						break
+					}
+				}
+			}
+
+			tickers, err := uc.tickers.GetTickers(entities.ExchangeFutures.Name())
+			if err != nil {
+				continue
+			}
+
+			for symbol, ticker := range tickers {
+				if ticker.NextFundingTimestamp <= ts {
+					continue
+				}
+
+				// a funding time due and not paid yet is kept until it is settled
+				funding, ok := schedule[symbol]
+				if ok && !funding.IsSettled() && funding.FundingTS <= ts {
+					continue
+				}
+
+				if !ok {
+					funding = &entities.Funding{Symbol: entities.Symbol(symbol)}
+					schedule[symbol] = funding
+				}
+
+				save := funding.FundingTS != ticker.NextFundingTimestamp || ts-funding.UpdateTS >= fundingSaveInterval
+
+				funding.FundingTS = ticker.NextFundingTimestamp
+				funding.Rate = ticker.LastFundingRate
+				funding.Price = markPrice(ticker)
+
+				if save {
+					funding.UpdateTS = ts
+					if err := uc.funding.SaveFunding(ctx, funding); err != nil {
+						uc.log.Error(fmt.Sprintf("ProcessFunding:SaveFunding [%+v] error: %v", *funding, err))
+					}
+				}
+			}
+		}
+	}
+}
+
+// settleFunding pays the funding time of the symbol for its open positions and stores it as paid in one transaction,
+// the missed times are paid at the rate and the price stored for the next one
+func (uc *Usecase) settleFunding(ctx context.Context, funding *entities.Funding, fundingTS int64) error {
+	positions, err := uc.position.SelectOpenPositions(ctx)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("settleFunding:SelectOpenPositions error: %v", err))
+		return err
+	}
+
+	muWallet.Lock()
+	defer muWallet.Unlock()
+
+	settled := *funding
+	settled.SettledTS = fundingTS
+	settled.UpdateTS = entities.TS()
+
+	err = uc.funding.WithTx(ctx, func(ctx context.Context) error {
+		if funding.Rate != 0 && funding.Price != 0 {
+			for _, position := range positions {
+				if position.Exchange != entities.ExchangeFutures || position.Symbol != funding.Symbol {
+					continue
+				}
+
+				if err := uc.payFunding(ctx, position, funding); err != nil {
+					uc.log.Error(fmt.Sprintf("settleFunding:payFunding [%+v] error: %v", *position, err))
+					return err
+				}
+			}
+		}
+
+		if err := uc.funding.UpdateFundingSettled(ctx, &settled); err != nil {
+			uc.log.Error(fmt.Sprintf("settleFunding:UpdateFundingSettled [%+v] error: %v", settled, err))
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	funding.SettledTS = fundingTS
+
+	return nil
+}
+
+func (uc *Usecase) payFunding(ctx context.Context, position *entities.Position, funding *entities.Funding) error {
+	amount := position.FundingFee(funding.Price, funding.Rate)
+	if amount == 0 {
+		return nil
+	}
+
+	if amount > 0 {
+		balance := entities.Balance{
+			Coin:  position.Symbol.GetCoins().CoinBase,
+			Total: amount,
+		}
+
+		if err := uc.AppendBalance(ctx, position.Exchange, position.AccountUID, balance); err != nil {
+			return err
+		}
+	} else {
+		if err := uc.chargeFunding(ctx, position, -amount); err != nil {
+			return err
+		}
+	}
+
+	transaction := entities.NewTransaction(position.AccountUID, position.Exchange, position.Symbol, entities.TransactionTypeFunding, amount)
+	if err := uc.AppendTransaction(ctx, transaction); err != nil {
+		uc.log.Error(fmt.Sprintf("payFunding:AppendTransaction [%+v] error: %v", *transaction, err))
+		return err
+	}
+
+	return nil
+}
+
+// chargeFunding takes the funding payment from the wallet and the collateral coins,
+// the rest they can not pay is taken from the position margin or is a loss of the insurance fund
+// when the position has no margin to give
+func (uc *Usecase) chargeFunding(ctx context.Context, position *entities.Position, amount float64) error {
+	coin := position.Symbol.GetCoins().CoinBase
+
+	total, _, err := uc.GetBalanceCoin(ctx, position.Exchange, position.AccountUID, coin)
+	if err != nil && !errors.Is(err, apperror.ErrBalanceNotFound) {
+		return err
+	}
+
+	if shortfall := amount - math.Max(total, 0); shortfall > 0 {
+		converted, err := uc.ConvertCollateral(ctx, position.Exchange, position.AccountUID, coin, shortfall)
+		if err != nil {
+			uc.log.Error(fmt.Sprintf("chargeFunding:ConvertCollateral [%+v] error: %v", *position, err))
+			return err
+		}
+		total += converted
+	}
+
+	paid := math.Min(amount, math.Max(total, 0))
+	if paid > 0 {
+		balance := entities.Balance{
+			Coin:  coin,
+			Total: paid,
+		}
+
+		if err := uc.SubtractBalance(ctx, position.Exchange, position.AccountUID, balance); err != nil {
+			return err
+		}
+	}
+
+	rest := amount - paid
+	if rest <= 0 {
+		return nil
+	}
+
+	uc.log.Info(fmt.Sprintf("chargeFunding [%+v] amount: %v, paid: %v, rest: %v", *position, amount, paid, rest))
+
+	if position.ChargeMargin(rest) {
+		position.UpdateTS = entities.TS()
+		return uc.SavePosition(ctx, position)
+	}
+
+	fund := entities.NewInsuranceFund(position.AccountUID, position.Exchange, position.Symbol, coin, -rest)
+	if err := uc.insurance.InsertInsuranceFund(ctx, fund); err != nil {
+		uc.log.Error(fmt.Sprintf("chargeFunding:InsertInsuranceFund [%+v] error: %v", *fund, err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/entities"
+)
+
+// InsuranceFundBalance returns the balance of the insurance fund of the exchange in the coin
+func (uc *Usecase) InsuranceFundBalance(ctx context.Context, exchange entities.Exchange, coin entities.Coin) (float64, error) {
+	balance, err := uc.insurance.SelectInsuranceFundBalance(ctx, exchange, coin)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("InsuranceFundBalance:SelectInsuranceFundBalance [exchange: %s, coin: %s] error: %v", exchange, coin, err))
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// InsuranceFundDeposit tops up the insurance fund of the exchange, the entry has no account
+func (uc *Usecase) InsuranceFundDeposit(ctx context.Context, exchange entities.Exchange, coin entities.Coin, amount float64) (float64, error) {
+	if exchange != entities.ExchangeFutures {
+		return 0, apperror.ErrExchangeIsNotValid
+	}
+
+	if amount <= 0 {
+		return 0, apperror.ErrAmountIsNotValid
+	}
+
+	muWallet.Lock()
+	defer muWallet.Unlock()
+
+	fund := entities.NewInsuranceFund(entities.AccountUID(uuid.Nil.String()), exchange, "", coin, amount)
+	if err := uc.insurance.InsertInsuranceFund(ctx, fund); err != nil {
+		uc.log.Error(fmt.Sprintf("InsuranceFundDeposit:InsertInsuranceFund [%+v] error: %v", *fund, err))
+		return 0, err
+	}
+
+	return uc.InsuranceFundBalance(ctx, exchange, coin)
+}
@@ -7,6 +7,7 @@ import (
 
 	"DemoExchange/internal/app/entities"
 	"DemoExchange/internal/app/markets"
+	"DemoExchange/internal/app/orderbook"
 	"DemoExchange/internal/app/tickers"
 	"DemoExchange/internal/app/usecase/orders"
 )
@@ -22,16 +23,20 @@ type AccountStorage interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 	InsertAccount(ctx context.Context, account *entities.Account) error
 	UpdatePositionMode(ctx context.Context, account *entities.Account) error
+	UpdateFeeTier(ctx context.Context, account *entities.Account) error
 	SelectAccount(ctx context.Context, service, userID string) (*entities.Account, error)
 	SelectAccountByUID(ctx context.Context, accountUID entities.AccountUID) (*entities.Account, error)
+	SelectSubAccounts(ctx context.Context, masterUID entities.AccountUID) ([]*entities.Account, error)
 }
 
 type APIKeyStorage interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 	InsertAccountKey(ctx context.Context, key *entities.Key) error
 	UpdateAccountKey(ctx context.Context, key *entities.Key) error
+	UpdateAccountKeySettings(ctx context.Context, key *entities.Key) error
+	UpdateAccountKeySecret(ctx context.Context, key *entities.Key) error
 	SelectAccountKeys(ctx context.Context, accountUID entities.AccountUID) ([]entities.Key, error)
-	SelectAccountUID(ctx context.Context, token entities.Token) (entities.AccountUID, error)
+	SelectAccountKey(ctx context.Context, token entities.Token) (*entities.Key, error)
 }
 
 type WalletStorage interface {
@@ -40,16 +45,22 @@ type WalletStorage interface {
 	AppendTotalCoin(ctx context.Context, wallet entities.Wallet) error
 	SubtractTotalCoin(ctx context.Context, wallet entities.Wallet) error
 	SetHoldCoin(ctx context.Context, wallet entities.Wallet) error
+	DeleteAccountWallets(ctx context.Context, accountUID entities.AccountUID) error
 }
 
 type OrderStorage interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 	InsertOrder(ctx context.Context, order *entities.Order) error
 	SelectOrder(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, orderUID string) (*entities.Order, error)
+	SelectOrderByID(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, orderID int64) (*entities.Order, error)
+	SelectOrderByClientID(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, clientOrderID string) (*entities.Order, error)
 	UpdateOrder(ctx context.Context, order *entities.Order) error
 	SelectOrders(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, statuses []entities.OrderStatus, limit int) ([]*entities.Order, error)
 	SelectPendingOrders(ctx context.Context) ([]*entities.Order, error)
 	SelectPendingOrdersBySymbol(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol *entities.Symbol) ([]*entities.Order, error)
+	SelectGroupOrders(ctx context.Context, accountUID entities.AccountUID, groupUID string) ([]*entities.Order, error)
+	SelectAccountVolume(ctx context.Context, accountUID entities.AccountUID, from int64) (float64, error)
+	ArchiveAccountOrders(ctx context.Context, accountUID entities.AccountUID, archiveTS int64) error
 }
 
 type PositionStorage interface {
@@ -60,13 +71,42 @@ type PositionStorage interface {
 	SelectPositionsBySymbol(ctx context.Context, accountUID entities.AccountUID, symbol entities.Symbol) (map[entities.PositionSide]*entities.Position, error)
 	SelectAccountPositions(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID) ([]*entities.Position, error)
 	SelectAccountOpenPositions(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID) ([]*entities.Position, error)
+	SelectSymbolOpenPositions(ctx context.Context, exchange entities.Exchange, symbol entities.Symbol) ([]*entities.Position, error)
 	SelectOpenPositions(ctx context.Context) ([]*entities.Position, error)
+	DeleteAccountPositions(ctx context.Context, accountUID entities.AccountUID) error
 }
 
 type TransactionStorage interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 	InsertTransaction(ctx context.Context, transaction *entities.Transaction) error
 	SelectAccountTransactions(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, filter entities.TransactionFilter) ([]*entities.Transaction, error)
+	ArchiveAccountTransactions(ctx context.Context, accountUID entities.AccountUID, archiveTS int64) error
+}
+
+type TradeStorage interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	InsertTrade(ctx context.Context, trade *entities.Trade) error
+	SelectAccountTrades(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, filter entities.TradeFilter) ([]*entities.Trade, error)
+	ArchiveAccountTrades(ctx context.Context, accountUID entities.AccountUID, archiveTS int64) error
+}
+
+type InsuranceStorage interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	InsertInsuranceFund(ctx context.Context, fund *entities.InsuranceFund) error
+	SelectInsuranceFundBalance(ctx context.Context, exchange entities.Exchange, coin entities.Coin) (float64, error)
+}
+
+type FundingStorage interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	SelectFundings(ctx context.Context) ([]*entities.Funding, error)
+	SaveFunding(ctx context.Context, funding *entities.Funding) error
+	UpdateFundingSettled(ctx context.Context, funding *entities.Funding) error
+}
+
+type Stream[K comparable, V any] interface {
+	Subscribe(key K) (<-chan V, func())
+	Publish(key K, value V)
+	HasSubscribers(key K) bool
 }
 
 type Cache[K comparable, V any] interface {
@@ -95,6 +135,10 @@ type Markets interface {
 	GetMarketWithContext(ctx context.Context, exchange, market string) (markets.Market, error)
 }
 
+type Orderbook interface {
+	GetOrderbook(ctx context.Context, exchange, symbol, limit string) (*orderbook.Orderbook, error)
+}
+
 type Logger interface {
 	Info(args ...interface{})
 	Error(args ...interface{})
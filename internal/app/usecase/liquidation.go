@@ -0,0 +1,280 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"DemoExchange/internal/app/entities"
+	"DemoExchange/internal/app/tickers"
+)
+
+const (
+	// liquidationStep is the share of the position closed by one step of the liquidation
+	liquidationStep = 0.25
+	// liquidationMinNotional is the notional below which the rest of the position is closed at once
+	liquidationMinNotional = 100
+)
+
+// markPrice returns the mark price of the ticker, the last price is used when the mark price is unknown
+func markPrice(ticker tickers.Ticker) float64 {
+	if ticker.PriceMark > 0 {
+		return ticker.PriceMark
+	}
+
+	return ticker.Last
+}
+
+func (uc *Usecase) marginBrackets(symbol entities.Symbol) entities.MarginBrackets {
+	if brackets, ok := uc.cfg.SymbolMarginBrackets[symbol]; ok {
+		return brackets
+	}
+
+	return uc.cfg.MarginBrackets
+}
+
+// GetMarginBracket returns the maintenance margin bracket of the symbol for the position notional
+func (uc *Usecase) GetMarginBracket(symbol entities.Symbol, notional float64) entities.MarginBracket {
+	return uc.marginBrackets(symbol).Find(notional)
+}
+
+// checkPositionLiquidation reduces the position step by step while its margin balance is at the maintenance margin,
+// cross positions are checked together against the margin they share. It reports whether the position is closed
+func (uc *Usecase) checkPositionLiquidation(ctx context.Context, position *entities.Position) bool {
+	if position.MarginType == entities.MarginTypeCross {
+		return uc.checkCrossLiquidation(ctx, position)
+	}
+
+	ticker, err := uc.tickers.GetTickerWithContext(ctx, position.Exchange.Name(), position.Symbol.String())
+	if err != nil {
+		return false
+	}
+
+	position.CalcMarginBalance(markPrice(ticker))
+	position.CalcMaintMargin(uc.marginBrackets(position.Symbol))
+
+	if position.MarginBalance > position.MaintMargin {
+		return false
+	}
+
+	if uc.cancelLiquidationOrders(ctx, position.Exchange, position.AccountUID, &position.Symbol) {
+		return false
+	}
+
+	muWallet.Lock()
+	defer muWallet.Unlock()
+
+	err = uc.position.WithTx(ctx, func(ctx context.Context) error {
+		return uc.liquidatePosition(ctx, position, position.MarginBalance <= 0)
+	})
+
+	return err == nil && position.Amount == 0
+}
+
+// checkCrossLiquidation reduces the cross positions of the account settled in the coin of the position
+// while their margin balance with the unrealised pnl is at their maintenance margin,
+// the position with the largest maintenance margin is reduced first
+func (uc *Usecase) checkCrossLiquidation(ctx context.Context, position *entities.Position) bool {
+	coin := position.Symbol.GetCoins().CoinBase
+
+	positions, cross, err := uc.crossMargin(ctx, position.Exchange, position.AccountUID, coin)
+	if err != nil || len(positions) == 0 || !cross.IsLiquidated() {
+		return false
+	}
+
+	uc.log.Info(fmt.Sprintf("checkCrossLiquidation [AccountUID: %s, coin: %s, cross: %+v]", position.AccountUID, coin, cross))
+
+	if uc.cancelLiquidationOrders(ctx, position.Exchange, position.AccountUID, nil) {
+		return false
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].MaintMargin > positions[j].MaintMargin
+	})
+
+	bankrupt := cross.MarginBalance() <= 0
+	if !bankrupt {
+		positions = positions[:1]
+	}
+
+	muWallet.Lock()
+	defer muWallet.Unlock()
+
+	err = uc.position.WithTx(ctx, func(ctx context.Context) error {
+		for _, p := range positions {
+			if err := uc.liquidatePosition(ctx, p, bankrupt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return err == nil && position.Amount == 0
+}
+
+// cancelLiquidationOrders cancels the pending orders of the symbol, or of all symbols when it is nil,
+// to release their hold before the liquidation. It reports whether any order has just been cancelled
+func (uc *Usecase) cancelLiquidationOrders(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol *entities.Symbol) bool {
+	pendingOrders, err := uc.order.SelectPendingOrdersBySymbol(ctx, exchange, accountUID, symbol)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("cancelLiquidationOrders:SelectPendingOrdersBySymbol [account_uid: %v, symbol: %v] error: %v", accountUID, symbol, err))
+		return false
+	}
+
+	cancelled := false
+	for _, o := range pendingOrders {
+		if o, ok := uc.cacheOrders.Get(o.OrderUID); ok && !o.Status.IsFinal() {
+			o.Status = entities.OrderStatusCancelled
+			o.UpdateTS = entities.TS()
+			cancelled = true
+		}
+	}
+
+	return cancelled
+}
+
+// crossMargin returns the open cross positions of the account settled in the coin, with their margin at the mark price
+func (uc *Usecase) crossMargin(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin) ([]*entities.Position, entities.CrossMargin, error) {
+	var cross entities.CrossMargin
+
+	exchangeTickers, err := uc.tickers.GetTickers(exchange.Name())
+	if err != nil {
+		return nil, cross, err
+	}
+
+	total, _, err := uc.GetBalanceCoin(ctx, exchange, accountUID, coin)
+	if err != nil {
+		return nil, cross, err
+	}
+
+	positions, err := uc.position.SelectAccountOpenPositions(ctx, exchange, accountUID)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("crossMargin:SelectAccountOpenPositions [AccountUID: %s] error: %v", accountUID, err))
+		return nil, cross, err
+	}
+
+	result := make([]*entities.Position, 0, len(positions))
+
+	for _, p := range positions {
+		if p.MarginType != entities.MarginTypeCross || p.Symbol.GetCoins().CoinBase != coin {
+			continue
+		}
+
+		if cached, ok := uc.cachePositions.Get(p.PositionUID); ok {
+			p = cached
+		}
+
+		ticker, ok := exchangeTickers[p.Symbol.String()]
+		if !ok {
+			return nil, cross, tickers.ErrTickerNotFound
+		}
+
+		p.CalcMarginBalance(markPrice(ticker))
+		p.CalcMaintMargin(uc.marginBrackets(p.Symbol))
+		result = append(result, p)
+	}
+
+	return result, entities.NewCrossMargin(total, result), nil
+}
+
+// liquidatePosition closes a step of the position at the mark price, or all of it when full is set
+// or the rest is too small. The liquidation fee goes to the insurance fund
+// and the margin balance of the closed part left after it is settled with the wallet
+func (uc *Usecase) liquidatePosition(ctx context.Context, position *entities.Position, full bool) error {
+	coins := position.Symbol.GetCoins()
+
+	amount := math.Abs(position.Amount)
+	if amount == 0 {
+		return nil
+	}
+
+	closeAmount := amount * liquidationStep
+	if full || (amount-closeAmount)*position.MarkPrice < liquidationMinNotional {
+		closeAmount = amount
+	}
+
+	share := closeAmount / amount
+	pnl := position.UnrealisedPnl * share
+	margin := position.Margin * share
+
+	fee := closeAmount * position.MarkPrice * uc.cfg.LiquidationFee
+	if position.MarginType == entities.MarginTypeIsolated && fee > margin+pnl {
+		fee = math.Max(margin+pnl, 0)
+	}
+
+	remaining := margin + pnl - fee
+
+	position.Amount = math.Copysign(amount-closeAmount, position.Amount)
+	position.Margin -= margin
+	if position.HoldAmount > amount-closeAmount {
+		position.HoldAmount = amount - closeAmount
+	}
+
+	if err := uc.updatePosition(ctx, position); err != nil {
+		uc.log.Error(fmt.Sprintf("liquidatePosition:updatePosition [%+v] error: %v", *position, err))
+		return err
+	}
+
+	position.CalcMarginBalance(position.MarkPrice)
+
+	uc.log.Info(fmt.Sprintf("liquidatePosition [%+v] amount: %v, pnl: %v, fee: %v", *position, closeAmount, pnl, fee))
+
+	transaction := entities.NewTransaction(position.AccountUID, position.Exchange, position.Symbol, entities.TransactionTypeLiquidation, pnl)
+	transaction.Price = position.MarkPrice
+	if err := uc.AppendTransaction(ctx, transaction); err != nil {
+		uc.log.Error(fmt.Sprintf("liquidatePosition:AppendTransaction [%+v] error: %v", *transaction, err))
+		return err
+	}
+
+	if remaining > 0 {
+		balance := entities.Balance{
+			Coin:  coins.CoinBase,
+			Total: remaining,
+		}
+		if err := uc.AppendBalance(ctx, position.Exchange, position.AccountUID, balance); err != nil {
+			return err
+		}
+	}
+
+	// the loss beyond the margin is taken from the wallet of a cross position and is a deficit otherwise
+	deficit := math.Max(-remaining, 0)
+
+	if deficit > 0 && position.MarginType == entities.MarginTypeCross {
+		total, _, err := uc.GetBalanceCoin(ctx, position.Exchange, position.AccountUID, coins.CoinBase)
+		if err != nil {
+			return err
+		}
+
+		balance := entities.Balance{
+			Coin:  coins.CoinBase,
+			Total: math.Min(deficit, math.Max(total, 0)),
+		}
+		if err := uc.SubtractBalance(ctx, position.Exchange, position.AccountUID, balance); err != nil {
+			return err
+		}
+
+		deficit -= balance.Total
+	}
+
+	if fee > 0 {
+		transaction := entities.NewTransaction(position.AccountUID, position.Exchange, position.Symbol, entities.TransactionTypeLiquidationFee, -fee)
+		transaction.Price = position.MarkPrice
+		if err := uc.AppendTransaction(ctx, transaction); err != nil {
+			uc.log.Error(fmt.Sprintf("liquidatePosition:AppendTransaction [%+v] error: %v", *transaction, err))
+			return err
+		}
+
+		fund := entities.NewInsuranceFund(position.AccountUID, position.Exchange, position.Symbol, coins.CoinBase, fee)
+		if err := uc.insurance.InsertInsuranceFund(ctx, fund); err != nil {
+			uc.log.Error(fmt.Sprintf("liquidatePosition:InsertInsuranceFund [%+v] error: %v", *fund, err))
+			return err
+		}
+	}
+
+	if deficit > 0 {
+		return uc.coverBankruptcy(ctx, position, closeAmount, deficit)
+	}
+
+	return nil
+}
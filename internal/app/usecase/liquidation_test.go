@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"DemoExchange/internal/app/entities"
+	"DemoExchange/internal/app/usecase/cache"
+	"DemoExchange/internal/app/usecase/stream"
+)
+
+type fakeLogger struct{}
+
+func (fakeLogger) Info(args ...interface{})  {}
+func (fakeLogger) Error(args ...interface{}) {}
+
+type fakePositionStorage struct {
+	PositionStorage
+}
+
+func (fakePositionStorage) UpdatePosition(ctx context.Context, position *entities.Position) error {
+	return nil
+}
+
+func (fakePositionStorage) SelectSymbolOpenPositions(ctx context.Context, exchange entities.Exchange, symbol entities.Symbol) ([]*entities.Position, error) {
+	return nil, nil
+}
+
+type fakeWalletStorage struct {
+	WalletStorage
+	balances entities.Balances
+}
+
+func (f *fakeWalletStorage) SelectBalances(ctx context.Context, wallet entities.Wallet) (entities.Balances, error) {
+	balances := make(entities.Balances, len(f.balances))
+	for coin, balance := range f.balances {
+		balances[coin] = balance
+	}
+
+	return balances, nil
+}
+
+func (f *fakeWalletStorage) AppendTotalCoin(ctx context.Context, wallet entities.Wallet) error {
+	balance := f.balances[wallet.Balance.Coin]
+	balance.Coin = wallet.Balance.Coin
+	balance.Total += wallet.Balance.Total
+	f.balances[wallet.Balance.Coin] = balance
+
+	return nil
+}
+
+func (f *fakeWalletStorage) SubtractTotalCoin(ctx context.Context, wallet entities.Wallet) error {
+	balance := f.balances[wallet.Balance.Coin]
+	balance.Total -= wallet.Balance.Total
+	f.balances[wallet.Balance.Coin] = balance
+
+	return nil
+}
+
+type fakeTransactionStorage struct {
+	TransactionStorage
+	transactions []*entities.Transaction
+}
+
+func (f *fakeTransactionStorage) InsertTransaction(ctx context.Context, transaction *entities.Transaction) error {
+	f.transactions = append(f.transactions, transaction)
+	return nil
+}
+
+type fakeInsuranceStorage struct {
+	InsuranceStorage
+	funds []*entities.InsuranceFund
+}
+
+func (f *fakeInsuranceStorage) InsertInsuranceFund(ctx context.Context, fund *entities.InsuranceFund) error {
+	f.funds = append(f.funds, fund)
+	return nil
+}
+
+func (f *fakeInsuranceStorage) SelectInsuranceFundBalance(ctx context.Context, exchange entities.Exchange, coin entities.Coin) (float64, error) {
+	var balance float64
+	for _, fund := range f.funds {
+		balance += fund.Amount
+	}
+
+	return balance, nil
+}
+
+func TestLiquidatePosition(t *testing.T) {
+	cases := []struct {
+		name         string
+		marginType   entities.MarginType
+		amount       float64
+		mark         float64
+		full         bool
+		wallet       float64
+		fund         float64
+		leftAmount   float64
+		leftMargin   float64
+		leftWallet   float64
+		transactions []float64
+		funds        []float64
+	}{
+		{
+			name:         "isolated step",
+			marginType:   entities.MarginTypeIsolated,
+			amount:       10,
+			mark:         91,
+			leftAmount:   7.5,
+			leftMargin:   75,
+			leftWallet:   0.225,
+			transactions: []float64{-22.5, -2.275},
+			funds:        []float64{2.275},
+		},
+		{
+			name:         "small rest is closed at once",
+			marginType:   entities.MarginTypeIsolated,
+			amount:       1,
+			mark:         91,
+			leftWallet:   0.09,
+			transactions: []float64{-9, -0.91},
+			funds:        []float64{0.91},
+		},
+		{
+			name:         "isolated bankrupt, deficit covered by the fund",
+			marginType:   entities.MarginTypeIsolated,
+			amount:       10,
+			mark:         89,
+			full:         true,
+			fund:         50,
+			transactions: []float64{-110},
+			funds:        []float64{50, -10},
+		},
+		{
+			name:         "cross bankrupt, deficit taken from the wallet then the fund",
+			marginType:   entities.MarginTypeCross,
+			amount:       10,
+			mark:         89,
+			full:         true,
+			wallet:       10,
+			transactions: []float64{-110, -8.9},
+			funds:        []float64{8.9, -8.9},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			wallet := &fakeWalletStorage{balances: entities.Balances{"USDT": {Coin: "USDT", Total: c.wallet}}}
+			transactions := &fakeTransactionStorage{}
+			insurance := &fakeInsuranceStorage{}
+
+			if c.fund > 0 {
+				insurance.funds = append(insurance.funds, entities.NewInsuranceFund("", entities.ExchangeFutures, "BTC/USDT", "USDT", c.fund))
+			}
+
+			uc := &Usecase{
+				cfg:            Config{LiquidationFee: 0.01},
+				position:       fakePositionStorage{},
+				wallet:         wallet,
+				transaction:    transactions,
+				insurance:      insurance,
+				cachePositions: cache.New[string, *entities.Position](fakeLogger{}),
+				userData:       stream.New[entities.AccountUID, *entities.UserEvent](1, fakeLogger{}),
+				log:            fakeLogger{},
+			}
+
+			position := &entities.Position{
+				AccountUID: "account",
+				Exchange:   entities.ExchangeFutures,
+				Symbol:     "BTC/USDT",
+				Mode:       entities.PositionModeOneway,
+				MarginType: c.marginType,
+				Leverage:   10,
+				Amount:     c.amount,
+				Price:      100,
+				Margin:     c.amount * 100 / 10,
+			}
+			position.CalcMarginBalance(c.mark)
+
+			err := uc.liquidatePosition(context.Background(), position, c.full)
+			assert.NoError(t, err)
+
+			assert.InDelta(t, c.leftAmount, position.Amount, 1e-9)
+			assert.InDelta(t, c.leftMargin, position.Margin, 1e-9)
+			assert.InDelta(t, c.leftWallet, wallet.balances["USDT"].Total, 1e-9)
+
+			if assert.Len(t, transactions.transactions, len(c.transactions)) {
+				for i, amount := range c.transactions {
+					assert.InDelta(t, amount, transactions.transactions[i].Amount, 1e-9)
+					assert.Equal(t, c.mark, transactions.transactions[i].Price)
+				}
+			}
+
+			if assert.Len(t, insurance.funds, len(c.funds)) {
+				for i, amount := range c.funds {
+					assert.InDelta(t, amount, insurance.funds[i].Amount, 1e-9)
+				}
+			}
+		})
+	}
+}
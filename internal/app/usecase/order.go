@@ -2,13 +2,17 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	"DemoExchange/internal/app/apperror"
 	"DemoExchange/internal/app/entities"
 	"DemoExchange/internal/app/usecase/orders"
+	orderRepo "DemoExchange/internal/app/usecase/repo/order"
 )
 
 const (
@@ -19,18 +23,20 @@ const (
 
 var muOrder = &sync.RWMutex{}
 
+// resettingAccounts are the accounts not accepting new orders while they are reset, guarded by muOrder
+var resettingAccounts = make(map[entities.AccountUID]struct{})
+
 func (uc *Usecase) NewOrder(ctx context.Context, o *entities.Order) error {
-	var err error
+	account, err := uc.GetAccountByUID(ctx, o.AccountUID)
+	if err != nil {
+		return err
+	}
 
 	if o.Exchange == entities.ExchangeFutures {
-		account, err := uc.GetAccountByUID(ctx, o.AccountUID)
-		if err != nil {
-			return err
-		}
 		o.PositionMode = account.PositionMode
 	}
 
-	order, err := orders.NewOrder(ctx, uc.markets, o)
+	order, err := orders.NewOrder(ctx, uc.markets, uc.orderbook, o)
 	if err != nil {
 		return err
 	}
@@ -40,10 +46,16 @@ func (uc *Usecase) NewOrder(ctx context.Context, o *entities.Order) error {
 		return err
 	}
 
+	uc.setOrderFees(ctx, account, o)
+
 	if err := uc.order.WithTx(ctx, func(ctx context.Context) error {
 		muOrder.Lock()
 		defer muOrder.Unlock()
 
+		if _, ok := resettingAccounts[o.AccountUID]; ok {
+			return apperror.ErrAccountResetting
+		}
+
 		if err := order.HoldBalance(ctx, uc, uc.log); err != nil {
 			uc.log.Error(fmt.Sprintf("NewOrder:HoldBalance [%+v] error: %v", *order.GetOrder(), err))
 			return err
@@ -66,6 +78,85 @@ func (uc *Usecase) NewOrder(ctx context.Context, o *entities.Order) error {
 	return nil
 }
 
+// NewOCOOrder places a limit order and a stop-limit order linked by group_uid.
+// The balance is held once for the group, the leg closed first releases it and cancels the other.
+func (uc *Usecase) NewOCOOrder(ctx context.Context, limit, stop *entities.Order) error {
+	if limit.Exchange != entities.ExchangeSpot {
+		return apperror.ErrExchangeIsNotValid
+	}
+
+	if (limit.Side == entities.OrderSideSell && limit.Price <= stop.StopPrice) || (limit.Side == entities.OrderSideBuy && limit.Price >= stop.StopPrice) {
+		return apperror.ErrOCOPricesIsNotValid
+	}
+
+	account, err := uc.GetAccountByUID(ctx, limit.AccountUID)
+	if err != nil {
+		return err
+	}
+
+	groupUID := uuid.New().String()
+
+	legs := make([]*orders.Order, 0, 2)
+	for _, o := range []*entities.Order{limit, stop} {
+		o.GroupUID = groupUID
+
+		order, err := orders.NewOrder(ctx, uc.markets, uc.orderbook, o)
+		if err != nil {
+			return err
+		}
+
+		if err := order.Validate(ctx); err != nil {
+			uc.log.Error(fmt.Sprintf("NewOCOOrder:Validate [%+v] error: %v", *order.GetOrder(), err))
+			return err
+		}
+
+		uc.setOrderFees(ctx, account, o)
+
+		legs = append(legs, order)
+	}
+
+	// the hold covers the most expensive leg
+	holder := legs[0]
+	if stop.Price > limit.Price {
+		holder = legs[1]
+	}
+	limit.HoldPrice = holder.GetOrder().Price
+	stop.HoldPrice = holder.GetOrder().Price
+
+	if err := uc.order.WithTx(ctx, func(ctx context.Context) error {
+		muOrder.Lock()
+		defer muOrder.Unlock()
+
+		if _, ok := resettingAccounts[limit.AccountUID]; ok {
+			return apperror.ErrAccountResetting
+		}
+
+		if err := holder.HoldBalance(ctx, uc, uc.log); err != nil {
+			uc.log.Error(fmt.Sprintf("NewOCOOrder:HoldBalance [%+v] error: %v", *holder.GetOrder(), err))
+			return err
+		}
+
+		for _, order := range legs {
+			if err := uc.saveOrder(ctx, order.GetOrder()); err != nil {
+				uc.log.Error(fmt.Sprintf("NewOCOOrder:saveOrder [%+v] error: %v", *order.GetOrder(), err))
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, order := range legs {
+		go func(order *orders.Order) {
+			uc.chOrders <- order
+		}(order)
+	}
+
+	return nil
+}
+
 func (uc *Usecase) ProcessOrders(ctx context.Context) {
 	for {
 		select {
@@ -91,6 +182,14 @@ func (uc *Usecase) ProcessOrders(ctx context.Context) {
 					return
 				}
 
+				// the process may still send statuses after the order is closed
+				defer func() {
+					go func() {
+						for range ch {
+						}
+					}()
+				}()
+
 				for {
 					select {
 					case <-ctx.Done():
@@ -103,20 +202,37 @@ func (uc *Usecase) ProcessOrders(ctx context.Context) {
 
 						muOrder.Lock()
 
-						if status == entities.OrderStatusSuccess {
+						// a leg with fills owns the group, others check whether it is already closed
+						if o.GroupUID != "" && o.FilledAmount == 0 && uc.isOrderGroupClosed(ctx, o) {
+							// the shared hold is already released by the closed leg of the group
+							if !status.IsFinal() {
+								o.Status = entities.OrderStatusCancelled
+								muOrder.Unlock()
+								continue
+							}
+
+							o.Status = entities.OrderStatusCancelled
+
+							uc.updateOrder(ctx, o)
+							muOrder.Unlock()
+							return
+						}
+
+						if status == entities.OrderStatusSuccess || status == entities.OrderStatusPartiallyFilled {
 							uc.log.Info(fmt.Sprintf("ProcessOrders:AppendBalance [%+v]", *o))
 							if err := order.AppendBalance(ctx, uc, uc.log); err != nil {
 								uc.log.Error(fmt.Sprintf("ProcessOrders:AppendBalance [%+v] error: %v", *o, err))
 								status = entities.OrderStatusFailed
 								o.Error = err.Error()
+							} else {
+								uc.saveFill(ctx, o.Trade)
 							}
 
-							if o.Fee > 0 {
-								transaction := entities.NewTransaction(o.AccountUID, o.Exchange, o.Symbol, entities.TransactionTypeComission, -o.Fee)
-								if err := uc.AppendTransaction(ctx, transaction); err != nil {
-									uc.log.Error(fmt.Sprintf("ProcessOrders:AppendTransaction [%+v] error: %v", *transaction, err))
-								}
-							}
+							o.Trade = nil
+						}
+
+						if status == entities.OrderStatusPending && o.FilledAmount > 0 {
+							status = entities.OrderStatusPartiallyFilled
 						}
 
 						if status == entities.OrderStatusCancelled || status == entities.OrderStatusFailed {
@@ -131,9 +247,14 @@ func (uc *Usecase) ProcessOrders(ctx context.Context) {
 						o.Status = status
 
 						uc.updateOrder(ctx, o)
+
+						if o.GroupUID != "" && (status == entities.OrderStatusPartiallyFilled || status.IsFinal()) {
+							uc.cancelOrderGroup(ctx, o)
+						}
+
 						muOrder.Unlock()
 
-						if status == entities.OrderStatusSuccess || status == entities.OrderStatusCancelled || status == entities.OrderStatusFailed {
+						if status.IsFinal() {
 							return
 						}
 					}
@@ -152,16 +273,40 @@ func (uc *Usecase) ProcessPendingOrders(ctx context.Context) error {
 
 	uc.log.Info("ProcessPendingOrders: ", len(pendingOrders))
 
+	// legs of a group share the hold of the most expensive leg
+	groupHoldPrice := make(map[string]float64)
 	for _, o := range pendingOrders {
+		if o.GroupUID != "" && o.Price > groupHoldPrice[o.GroupUID] {
+			groupHoldPrice[o.GroupUID] = o.Price
+		}
+	}
+
+	for _, o := range pendingOrders {
+		if o.GroupUID != "" {
+			o.HoldPrice = groupHoldPrice[o.GroupUID]
+		}
+
 		if o.Exchange == entities.ExchangeFutures {
 			o.PositionMode = o.PositionSide.PositionMode()
 		}
 
-		order, err := orders.NewOrder(ctx, uc.markets, o)
+		order, err := orders.NewOrder(ctx, uc.markets, uc.orderbook, o)
 		if err != nil {
 			return err
 		}
 
+		// market data is not stored with the order, the default fees are used when it is not available
+		if err := order.SetMarket(ctx); err != nil {
+			uc.log.Error(fmt.Sprintf("ProcessPendingOrders:SetMarket [%+v] error: %v", *o, err))
+		}
+
+		account, err := uc.GetAccountByUID(ctx, o.AccountUID)
+		if err != nil {
+			uc.log.Error(fmt.Sprintf("ProcessPendingOrders:GetAccountByUID [%+v] error: %v", *o, err))
+		} else {
+			uc.setOrderFees(ctx, account, o)
+		}
+
 		go func() {
 			uc.chOrders <- order
 		}()
@@ -175,6 +320,37 @@ func (uc *Usecase) GetOrder(ctx context.Context, exchange entities.Exchange, acc
 	order, err := uc.order.SelectOrder(ctx, exchange, accountUID, orderUID)
 	if err != nil {
 		uc.log.Error(fmt.Sprintf("GetOrder:SelectOrder [account_uid: %v] [order_uid: %v] error: %v", accountUID, orderUID, err))
+		if errors.Is(err, orderRepo.ErrOrderNotFound) {
+			return nil, apperror.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetOrderByID finds the order by its numeric id
+func (uc *Usecase) GetOrderByID(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, orderID int64) (*entities.Order, error) {
+	order, err := uc.order.SelectOrderByID(ctx, exchange, accountUID, orderID)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("GetOrderByID:SelectOrderByID [account_uid: %v] [order_id: %v] error: %v", accountUID, orderID, err))
+		if errors.Is(err, orderRepo.ErrOrderNotFound) {
+			return nil, apperror.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetOrderByClientID finds the latest order placed with the client order id
+func (uc *Usecase) GetOrderByClientID(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, clientOrderID string) (*entities.Order, error) {
+	order, err := uc.order.SelectOrderByClientID(ctx, exchange, accountUID, clientOrderID)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("GetOrderByClientID:SelectOrderByClientID [account_uid: %v] [client_order_id: %v] error: %v", accountUID, clientOrderID, err))
+		if errors.Is(err, orderRepo.ErrOrderNotFound) {
+			return nil, apperror.ErrOrderNotFound
+		}
 		return nil, err
 	}
 
@@ -207,7 +383,14 @@ func (uc *Usecase) OrdersList(ctx context.Context, exchange entities.Exchange, a
 }
 
 func (uc *Usecase) saveOrder(ctx context.Context, order *entities.Order) error {
-	return uc.order.InsertOrder(ctx, order)
+	if err := uc.order.InsertOrder(ctx, order); err != nil {
+		if errors.Is(err, orderRepo.ErrClientOrderIDExists) {
+			return apperror.ErrDuplicateOrder
+		}
+		return err
+	}
+
+	return nil
 }
 
 func (uc *Usecase) updateOrder(ctx context.Context, order *entities.Order) {
@@ -227,11 +410,65 @@ func (uc *Usecase) updateOrder(ctx context.Context, order *entities.Order) {
 				time.Sleep(TimeoutRetry)
 				continue
 			}
+			uc.publishOrder(order)
 			return
 		}
 	}
 }
 
+// saveFill records the fill of the order and its comission
+func (uc *Usecase) saveFill(ctx context.Context, trade *entities.Trade) {
+	if err := uc.saveTrade(ctx, trade); err != nil {
+		uc.log.Error(fmt.Sprintf("saveFill:saveTrade [%+v] error: %v", *trade, err))
+	}
+
+	if trade.Fee > 0 {
+		transaction := entities.NewTransaction(trade.AccountUID, trade.Exchange, trade.Symbol, entities.TransactionTypeComission, -trade.Fee)
+		transaction.FeeType = trade.FeeType()
+		transaction.OrderUID = trade.OrderUID
+		if err := uc.AppendTransaction(ctx, transaction); err != nil {
+			uc.log.Error(fmt.Sprintf("saveFill:AppendTransaction [%+v] error: %v", *transaction, err))
+		}
+	}
+}
+
+// isOrderGroupClosed reports whether another leg of the group is closed or filled,
+// the group is taken as closed when it can not be read so that both legs are never filled
+func (uc *Usecase) isOrderGroupClosed(ctx context.Context, order *entities.Order) bool {
+	groupOrders, err := uc.order.SelectGroupOrders(ctx, order.AccountUID, order.GroupUID)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("isOrderGroupClosed:SelectGroupOrders [%+v] error: %v", *order, err))
+		return true
+	}
+
+	for _, o := range groupOrders {
+		if o.OrderUID != order.OrderUID && (o.Status.IsFinal() || o.FilledAmount > 0) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (uc *Usecase) cancelOrderGroup(ctx context.Context, order *entities.Order) {
+	groupOrders, err := uc.order.SelectGroupOrders(ctx, order.AccountUID, order.GroupUID)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("cancelOrderGroup:SelectGroupOrders [%+v] error: %v", *order, err))
+		return
+	}
+
+	for _, o := range groupOrders {
+		if o.OrderUID == order.OrderUID {
+			continue
+		}
+
+		if o, ok := uc.cacheOrders.Get(o.OrderUID); ok && !o.Status.IsFinal() {
+			o.Status = entities.OrderStatusCancelled
+			o.UpdateTS = entities.TS()
+		}
+	}
+}
+
 func (uc *Usecase) checkPresentPendingOrders(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol *entities.Symbol) error {
 	orders, err := uc.order.SelectPendingOrdersBySymbol(ctx, exchange, accountUID, symbol)
 	if err != nil {
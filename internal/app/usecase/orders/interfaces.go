@@ -5,6 +5,7 @@ import (
 
 	"DemoExchange/internal/app/entities"
 	"DemoExchange/internal/app/markets"
+	"DemoExchange/internal/app/orderbook"
 	"DemoExchange/internal/app/tickers"
 )
 
@@ -26,11 +27,14 @@ type Balance interface {
 	SetHoldBalance(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, balance entities.Balance) error
 	SubtractBalance(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, balance entities.Balance) error
 	AppendBalance(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, balance entities.Balance) error
+	GetCollateral(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin) (float64, error)
+	ConvertCollateral(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin, amount float64) (float64, error)
 }
 
 type Position interface {
 	GetPositionBySide(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol entities.Symbol, side entities.PositionSide) (*entities.Position, error)
 	SavePosition(ctx context.Context, position *entities.Position) error
+	GetMarginBracket(symbol entities.Symbol, notional float64) entities.MarginBracket
 }
 
 type Transaction interface {
@@ -45,6 +49,10 @@ type Markets interface {
 	GetMarketWithContext(ctx context.Context, exchange, market string) (markets.Market, error)
 }
 
+type Orderbook interface {
+	GetOrderbook(ctx context.Context, exchange, symbol, limit string) (*orderbook.Orderbook, error)
+}
+
 type Logger interface {
 	Info(args ...interface{})
 	Error(args ...interface{})
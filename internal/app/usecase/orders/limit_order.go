@@ -10,17 +10,22 @@ import (
 	"DemoExchange/internal/app/tickers"
 )
 
-const processTimeout = 5 * time.Second
+const (
+	processTimeout = 5 * time.Second
+	orderbookLimit = "100"
+)
 
 type LimitOrder struct {
-	order   *entities.Order
-	tickers Tickers
+	order     *entities.Order
+	tickers   Tickers
+	orderbook Orderbook
 }
 
-func NewLimitOrder(o *entities.Order) *LimitOrder {
+func NewLimitOrder(o *entities.Order, orderbook Orderbook) *LimitOrder {
 	return &LimitOrder{
-		order:   o,
-		tickers: tickers.New(),
+		order:     o,
+		tickers:   tickers.New(),
+		orderbook: orderbook,
 	}
 }
 
@@ -29,9 +34,103 @@ func (o *LimitOrder) Validate() error {
 		return apperror.ErrPriceIsNotValid
 	}
 
+	switch o.order.TimeInForce {
+	case entities.TimeInForceGTX:
+		return o.validatePostOnly()
+	case entities.TimeInForceIOC, entities.TimeInForceFOK:
+		return o.fillImmediately()
+	}
+
+	return o.takeCrossed()
+}
+
+// takeCrossed fills the part of the order crossing the orderbook when it is placed as taker at the book prices,
+// the rest of the order rests on the book
+func (o *LimitOrder) takeCrossed() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	levels, err := takenLevels(ctx, o.orderbook, o.order)
+	if err != nil {
+		// without the book only an order that does not cross the bid/ask can rest
+		if o.validatePostOnly() != nil {
+			return apperror.ErrOrderbookIsWrong
+		}
+		return nil
+	}
+
+	filled, avg := levels.Fill(o.order.Amount, func(price float64) bool { return crossesLimit(o.order, price) })
+	if filled > 0 {
+		o.order.Trade = entities.NewTrade(o.order, filled, avg, false)
+	}
+
+	return nil
+}
+
+// validatePostOnly rejects the order when it would cross the current bid/ask
+func (o *LimitOrder) validatePostOnly() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ticker, err := o.tickers.GetTickerWithContext(ctx, o.order.Exchange.Name(), o.order.Symbol.String())
+	if err != nil {
+		return apperror.ErrMarketPriceIsWrong
+	}
+
+	if (o.order.Side == entities.OrderSideBuy && ticker.Ask > 0 && o.order.Price >= ticker.Ask) || (o.order.Side == entities.OrderSideSell && ticker.Bid > 0 && o.order.Price <= ticker.Bid) {
+		return apperror.ErrOrderWouldImmediatelyMatch
+	}
+
+	return nil
+}
+
+// fillImmediately resolves IOC and FOK orders against the orderbook,
+// the fill is taken at the average price and the rest of an IOC order is cancelled
+func (o *LimitOrder) fillImmediately() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	levels, err := takenLevels(ctx, o.orderbook, o.order)
+	if err != nil {
+		return apperror.ErrOrderbookIsWrong
+	}
+
+	filled, avg := levels.Fill(o.order.Amount, func(price float64) bool { return crossesLimit(o.order, price) })
+
+	if filled == 0 || (o.order.TimeInForce == entities.TimeInForceFOK && filled < o.order.Amount) {
+		return apperror.ErrOrderWouldNotFill
+	}
+
+	o.order.Trade = entities.NewTrade(o.order, filled, avg, false)
+
 	return nil
 }
 
+// crossesLimit reports whether the price is acceptable for the limit price of the order
+func crossesLimit(order *entities.Order, price float64) bool {
+	if order.Side == entities.OrderSideBuy {
+		return price <= order.Price
+	}
+
+	return price >= order.Price
+}
+
+// crossedAmount returns the amount available in the orderbook at the limit price of the order,
+// the whole remaining amount is returned when the book is not available
+func crossedAmount(ctx context.Context, ob Orderbook, order *entities.Order, remaining float64) float64 {
+	levels, err := takenLevels(ctx, ob, order)
+	if err != nil {
+		return remaining
+	}
+
+	filled, _ := levels.Fill(remaining, func(price float64) bool { return crossesLimit(order, price) })
+	if filled == 0 {
+		return remaining
+	}
+
+	return filled
+}
+
 func (o *LimitOrder) Process(ctx context.Context) <-chan entities.OrderStatus {
 	ch := make(chan entities.OrderStatus)
 
@@ -45,6 +144,37 @@ func (o *LimitOrder) Process(ctx context.Context) <-chan entities.OrderStatus {
 		o.order.Status = entities.OrderStatusPending
 		ch <- entities.OrderStatusPending
 
+		remaining := o.order.RemainingAmount()
+
+		// IOC and FOK orders are filled against the orderbook on validation,
+		// the remainder of an order restored after a restart is cancelled
+		if o.order.TimeInForce == entities.TimeInForceIOC || o.order.TimeInForce == entities.TimeInForceFOK {
+			if o.order.Trade == nil {
+				ch <- entities.OrderStatusCancelled
+				return
+			}
+
+			if o.order.Trade.Amount < remaining {
+				ch <- entities.OrderStatusPartiallyFilled
+				ch <- entities.OrderStatusCancelled
+				return
+			}
+
+			ch <- entities.OrderStatusSuccess
+			return
+		}
+
+		// the part taken from the book on validation is filled before the order rests
+		if o.order.Trade != nil {
+			remaining -= o.order.Trade.Amount
+			if remaining <= 0 {
+				ch <- entities.OrderStatusSuccess
+				return
+			}
+
+			ch <- entities.OrderStatusPartiallyFilled
+		}
+
 		var (
 			ticker tickers.Ticker
 			err    error
@@ -56,7 +186,7 @@ func (o *LimitOrder) Process(ctx context.Context) <-chan entities.OrderStatus {
 				return
 			default:
 				status := o.order.Status
-				if status == entities.OrderStatusSuccess || status == entities.OrderStatusCancelled {
+				if status.IsFinal() {
 					ch <- status
 					return
 				}
@@ -68,14 +198,23 @@ func (o *LimitOrder) Process(ctx context.Context) <-chan entities.OrderStatus {
 				}
 
 				status = o.order.Status
-				if status == entities.OrderStatusSuccess || status == entities.OrderStatusCancelled {
+				if status.IsFinal() {
 					ch <- status
 					return
 				}
 
-				if (o.order.Side == "buy" && ticker.Last <= o.order.Price) || (o.order.Side == "sell" && ticker.Last >= o.order.Price) {
-					ch <- entities.OrderStatusSuccess
-					return
+				// the order rests on the book, so it is filled as maker at its price
+				if crossesLimit(o.order, ticker.Last) {
+					fill := crossedAmount(ctx, o.orderbook, o.order, remaining)
+					o.order.Trade = entities.NewTrade(o.order, fill, o.order.Price, true)
+
+					remaining -= fill
+					if remaining <= 0 {
+						ch <- entities.OrderStatusSuccess
+						return
+					}
+
+					ch <- entities.OrderStatusPartiallyFilled
 				}
 
 				time.Sleep(processTimeout)
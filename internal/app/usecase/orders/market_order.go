@@ -7,35 +7,106 @@ import (
 
 	"DemoExchange/internal/app/apperror"
 	"DemoExchange/internal/app/entities"
+	"DemoExchange/internal/app/orderbook"
 	"DemoExchange/internal/app/tickers"
 )
 
 type MarketOrder struct {
-	order   *entities.Order
-	tickers Tickers
+	order     *entities.Order
+	tickers   Tickers
+	orderbook Orderbook
 }
 
-func NewMarketOrder(o *entities.Order) *MarketOrder {
+func NewMarketOrder(o *entities.Order, orderbook Orderbook) *MarketOrder {
 	return &MarketOrder{
-		order:   o,
-		tickers: tickers.New(),
+		order:     o,
+		tickers:   tickers.New(),
+		orderbook: orderbook,
 	}
 }
 
+// Validate fills the order against the orderbook at the volume weighted price,
+// the part the book is too thin for is cancelled after the fill.
+// A reduce only order closing a position is filled at the last price when the book is not available
 func (o *MarketOrder) Validate() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	ticker, err := o.tickers.GetTickerWithContext(ctx, o.order.Exchange.Name(), o.order.Symbol.String())
+	levels, err := takenLevels(ctx, o.orderbook, o.order)
 	if err != nil {
-		return apperror.ErrMarketPriceIsWrong
+		if !o.order.ReduceOnly {
+			return apperror.ErrMarketPriceIsWrong
+		}
+
+		ticker, err := o.tickers.GetTickerWithContext(ctx, o.order.Exchange.Name(), o.order.Symbol.String())
+		if err != nil || ticker.Last <= 0 {
+			return apperror.ErrMarketPriceIsWrong
+		}
+
+		o.order.Price = ticker.Last
+		o.order.Trade = entities.NewTrade(o.order, o.order.Amount, ticker.Last, false)
+
+		return nil
+	}
+
+	filled, avg := levels.Fill(o.order.Amount, func(float64) bool { return true })
+	if filled == 0 {
+		return apperror.ErrOrderWouldNotFill
 	}
 
-	o.order.Price = ticker.Last
+	o.order.Price = avg
+	o.order.Trade = entities.NewTrade(o.order, filled, avg, false)
 
 	return nil
 }
 
+// takenLevels returns the side of the orderbook taken by the order
+func takenLevels(ctx context.Context, ob Orderbook, order *entities.Order) (orderbook.Levels, error) {
+	book, err := ob.GetOrderbook(ctx, order.Exchange.Name(), order.Symbol.String(), orderbookLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Side == entities.OrderSideBuy {
+		return book.Asks, nil
+	}
+
+	return book.Bids, nil
+}
+
+// sweepFill returns the amount taken from the orderbook and its average price, the part the book can not fill
+// is left to be cancelled as for a market order, the whole amount is filled at fallback when the book is not available
+func sweepFill(ctx context.Context, ob Orderbook, order *entities.Order, amount, fallback float64) (float64, float64) {
+	levels, err := takenLevels(ctx, ob, order)
+	if err != nil {
+		return amount, fallback
+	}
+
+	return levels.Fill(amount, func(float64) bool { return true })
+}
+
+// sendSweep fills the triggered order against the orderbook and sends its statuses,
+// the remainder the book is too thin for is cancelled
+func sendSweep(ctx context.Context, ch chan<- entities.OrderStatus, ob Orderbook, order *entities.Order, fallback float64) {
+	remaining := order.RemainingAmount()
+
+	filled, price := sweepFill(ctx, ob, order, remaining, fallback)
+	if filled <= 0 {
+		ch <- entities.OrderStatusCancelled
+		return
+	}
+
+	order.Trade = entities.NewTrade(order, filled, price, false)
+
+	if filled < remaining {
+		ch <- entities.OrderStatusPartiallyFilled
+		ch <- entities.OrderStatusCancelled
+		return
+	}
+
+	ch <- entities.OrderStatusSuccess
+}
+
 func (o *MarketOrder) Process(ctx context.Context) <-chan entities.OrderStatus {
 	ch := make(chan entities.OrderStatus)
 
@@ -53,6 +124,19 @@ func (o *MarketOrder) Process(ctx context.Context) <-chan entities.OrderStatus {
 			case <-ctx.Done():
 				return
 			default:
+				// an order restored after a restart was not filled on validation, its remainder is cancelled
+				// instead of being filled against a later book
+				if o.order.Trade == nil {
+					ch <- entities.OrderStatusCancelled
+					return
+				}
+
+				if o.order.Trade.Amount < o.order.RemainingAmount() {
+					ch <- entities.OrderStatusPartiallyFilled
+					ch <- entities.OrderStatusCancelled
+					return
+				}
+
 				ch <- entities.OrderStatusSuccess
 				return
 			}
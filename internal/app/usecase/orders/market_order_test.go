@@ -0,0 +1,168 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"DemoExchange/internal/app/entities"
+	"DemoExchange/internal/app/orderbook"
+)
+
+type fakeOrderbook struct {
+	book *orderbook.Orderbook
+	err  error
+}
+
+func (f fakeOrderbook) GetOrderbook(ctx context.Context, exchange, symbol, limit string) (*orderbook.Orderbook, error) {
+	return f.book, f.err
+}
+
+// statuses runs fn with a channel and returns the statuses it sent
+func statuses(fn func(ch chan<- entities.OrderStatus)) []entities.OrderStatus {
+	ch := make(chan entities.OrderStatus, 10)
+	fn(ch)
+	close(ch)
+
+	result := make([]entities.OrderStatus, 0)
+	for status := range ch {
+		result = append(result, status)
+	}
+
+	return result
+}
+
+func TestSendSweep(t *testing.T) {
+	book := &orderbook.Orderbook{
+		Asks: orderbook.Levels{{Price: 100, Amount: 1}, {Price: 102, Amount: 1}},
+		Bids: orderbook.Levels{{Price: 99, Amount: 0.5}},
+	}
+
+	cases := []struct {
+		name     string
+		side     entities.OrderSide
+		amount   float64
+		filled   float64
+		ob       fakeOrderbook
+		statuses []entities.OrderStatus
+		trade    float64
+		price    float64
+	}{
+		{
+			name:     "filled by the book",
+			side:     entities.OrderSideBuy,
+			amount:   2,
+			ob:       fakeOrderbook{book: book},
+			statuses: []entities.OrderStatus{entities.OrderStatusSuccess},
+			trade:    2,
+			price:    101,
+		},
+		{
+			name:     "thin book, remainder cancelled",
+			side:     entities.OrderSideBuy,
+			amount:   3,
+			ob:       fakeOrderbook{book: book},
+			statuses: []entities.OrderStatus{entities.OrderStatusPartiallyFilled, entities.OrderStatusCancelled},
+			trade:    2,
+			price:    101,
+		},
+		{
+			name:     "thin book, filled part of the order is kept",
+			side:     entities.OrderSideSell,
+			amount:   2,
+			filled:   1,
+			ob:       fakeOrderbook{book: book},
+			statuses: []entities.OrderStatus{entities.OrderStatusPartiallyFilled, entities.OrderStatusCancelled},
+			trade:    0.5,
+			price:    99,
+		},
+		{
+			name:     "empty book",
+			side:     entities.OrderSideSell,
+			amount:   1,
+			ob:       fakeOrderbook{book: &orderbook.Orderbook{}},
+			statuses: []entities.OrderStatus{entities.OrderStatusCancelled},
+		},
+		{
+			name:     "book not available, filled at the fallback",
+			side:     entities.OrderSideBuy,
+			amount:   1,
+			ob:       fakeOrderbook{err: errors.New("orderbook is not available")},
+			statuses: []entities.OrderStatus{entities.OrderStatusSuccess},
+			trade:    1,
+			price:    105,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			order := entities.NewOrder("account")
+			order.Exchange = entities.ExchangeSpot
+			order.Symbol = "BTC/USDT"
+			order.Side = c.side
+			order.Amount = c.amount
+			order.FilledAmount = c.filled
+
+			result := statuses(func(ch chan<- entities.OrderStatus) {
+				sendSweep(context.Background(), ch, c.ob, order, 105)
+			})
+
+			assert.Equal(t, c.statuses, result)
+
+			if c.trade == 0 {
+				assert.Nil(t, order.Trade)
+				return
+			}
+
+			if assert.NotNil(t, order.Trade) {
+				assert.InDelta(t, c.trade, order.Trade.Amount, 1e-9)
+				assert.InDelta(t, c.price, order.Trade.Price, 1e-9)
+			}
+		})
+	}
+}
+
+func TestMarketOrderProcess(t *testing.T) {
+	cases := []struct {
+		name     string
+		trade    float64
+		statuses []entities.OrderStatus
+	}{
+		{
+			name:     "filled",
+			trade:    2,
+			statuses: []entities.OrderStatus{entities.OrderStatusPending, entities.OrderStatusSuccess},
+		},
+		{
+			name:     "partially filled, remainder cancelled",
+			trade:    0.5,
+			statuses: []entities.OrderStatus{entities.OrderStatusPending, entities.OrderStatusPartiallyFilled, entities.OrderStatusCancelled},
+		},
+		{
+			name:     "restored without a fill",
+			statuses: []entities.OrderStatus{entities.OrderStatusPending, entities.OrderStatusCancelled},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			order := entities.NewOrder("account")
+			order.Exchange = entities.ExchangeSpot
+			order.Symbol = "BTC/USDT"
+			order.Side = entities.OrderSideBuy
+			order.Amount = 2
+			if c.trade > 0 {
+				order.Trade = entities.NewTrade(order, c.trade, 100, false)
+			}
+
+			result := make([]entities.OrderStatus, 0)
+			for status := range NewMarketOrder(order, fakeOrderbook{}).Process(context.Background()) {
+				result = append(result, status)
+			}
+
+			assert.Equal(t, c.statuses, result)
+		})
+	}
+}
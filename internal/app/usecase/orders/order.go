@@ -7,18 +7,20 @@ import (
 	"DemoExchange/internal/app/entities"
 )
 
+// fees used when the market data has no rates
 const (
 	OrderSpotFee    = 0.001
 	OrderFuturesFee = 0.0004
 )
 
 type Order struct {
-	order   *entities.Order
-	markets Markets
+	order     *entities.Order
+	markets   Markets
+	orderbook Orderbook
 }
 
-func NewOrder(ctx context.Context, markets Markets, order *entities.Order) (*Order, error) {
-	return &Order{order, markets}, nil
+func NewOrder(ctx context.Context, markets Markets, orderbook Orderbook, order *entities.Order) (*Order, error) {
+	return &Order{order, markets, orderbook}, nil
 }
 
 func (o *Order) GetOrder() *entities.Order {
@@ -36,26 +38,82 @@ func (o *Order) HoldBalance(ctx context.Context, uc Usecase, log Logger) error {
 	}
 }
 
+// UnholdBalance releases the hold of the not filled part of the order
 func (o *Order) UnholdBalance(ctx context.Context, uc Usecase, log Logger) error {
+	rest := *o.order
+	rest.Amount = o.order.RemainingAmount()
+	rest.Price = o.order.GetHoldPrice()
+	rest.Trade = nil
+
+	if rest.Amount <= 0 {
+		return nil
+	}
+
 	switch o.order.Exchange {
 	case entities.ExchangeSpot:
-		return NewOrderSpot(o.order).UnholdBalance(ctx, uc, log)
+		return NewOrderSpot(&rest).UnholdBalance(ctx, uc, log)
 	case entities.ExchangeFutures:
-		return NewOrderFutures(o.order).UnholdBalance(ctx, uc, log)
+		return NewOrderFutures(&rest).UnholdBalance(ctx, uc, log)
 	default:
 		return apperror.ErrExchangeIsNotValid
 	}
 }
 
+// AppendBalance settles the current fill of the order and accumulates it on the order
 func (o *Order) AppendBalance(ctx context.Context, uc Usecase, log Logger) error {
+	remaining := o.order.RemainingAmount()
+
+	// every fill is priced by the order type, a fill is never made up at the order price
+	if o.order.Trade == nil {
+		return apperror.ErrOrderWouldNotFill
+	}
+
+	trade := o.order.Trade
+	if trade.Amount > remaining {
+		trade.Amount = remaining
+	}
+
+	if trade.Amount <= 0 {
+		return apperror.ErrAmountIsNotValid
+	}
+
+	fill := *o.order
+	fill.Amount = trade.Amount
+	fill.Price = trade.Price
+	fill.HoldPrice = o.order.GetHoldPrice()
+
+	var err error
+
 	switch o.order.Exchange {
 	case entities.ExchangeSpot:
-		return NewOrderSpot(o.order).AppendBalance(ctx, uc, log)
+		err = NewOrderSpot(&fill).AppendBalance(ctx, uc, log)
 	case entities.ExchangeFutures:
-		return NewOrderFutures(o.order).AppendBalance(ctx, uc, log)
+		err = NewOrderFutures(&fill).AppendBalance(ctx, uc, log)
 	default:
-		return apperror.ErrExchangeIsNotValid
+		err = apperror.ErrExchangeIsNotValid
 	}
+
+	if err != nil {
+		return err
+	}
+
+	trade.Fee = fill.Fee
+	trade.FeeCoin = fill.FeeCoin
+
+	o.order.RealizedPnl = fill.RealizedPnl
+
+	// the fee estimated on hold is replaced by the fees of the fills
+	if o.order.FilledAmount == 0 {
+		o.order.Fee = 0
+	}
+
+	o.order.Fee += trade.Fee
+	o.order.FeeCoin = trade.FeeCoin
+	o.order.FeeType = trade.FeeType()
+	o.order.AvgPrice = (o.order.AvgPrice*o.order.FilledAmount + trade.Price*trade.Amount) / (o.order.FilledAmount + trade.Amount)
+	o.order.FilledAmount += trade.Amount
+
+	return nil
 }
 
 func (o *Order) Validate(ctx context.Context) error {
@@ -75,14 +133,28 @@ func (o *Order) Validate(ctx context.Context) error {
 		return apperror.ErrAmountIsNotValid
 	}
 
+	if o.order.TimeInForce == "" {
+		o.order.TimeInForce = entities.TimeInForceGTC
+	}
+
+	if !o.order.TimeInForce.IsValid() || (o.order.Type != entities.OrderTypeLimit && o.order.TimeInForce != entities.TimeInForceGTC) {
+		return apperror.ErrTimeInForceIsNotValid
+	}
+
 	var err error
 
 	switch o.order.Type {
 	case entities.OrderTypeMarket:
-		err = NewMarketOrder(o.order).Validate()
+		err = NewMarketOrder(o.order, o.orderbook).Validate()
 
 	case entities.OrderTypeLimit:
-		err = NewLimitOrder(o.order).Validate()
+		err = NewLimitOrder(o.order, o.orderbook).Validate()
+
+	case entities.OrderTypeStopMarket, entities.OrderTypeStopLimit:
+		err = NewStopOrder(o.order, o.orderbook).Validate()
+
+	case entities.OrderTypeTrailingStop:
+		err = NewTrailingStopOrder(o.order, o.orderbook).Validate()
 
 	default:
 		return apperror.ErrOrderTypeNotValid
@@ -92,14 +164,10 @@ func (o *Order) Validate(ctx context.Context) error {
 		return err
 	}
 
-	market, err := o.markets.GetMarketWithContext(ctx, o.order.Exchange.Name(), o.order.Symbol.String())
-	if err != nil {
+	if err := o.SetMarket(ctx); err != nil {
 		return err
 	}
 
-	o.order.Precision = market.Precision.Amount
-	o.order.Limit = market.Limits.Amount.Min
-
 	switch o.order.Exchange {
 	case entities.ExchangeFutures:
 		err = NewOrderFutures(o.order).Validate()
@@ -111,13 +179,49 @@ func (o *Order) Validate(ctx context.Context) error {
 	return err
 }
 
+// SetMarket loads the precision, limits and fees of the order symbol from the market data
+func (o *Order) SetMarket(ctx context.Context) error {
+	market, err := o.markets.GetMarketWithContext(ctx, o.order.Exchange.Name(), o.order.Symbol.String())
+	if err != nil {
+		o.setDefaultFees()
+		return err
+	}
+
+	o.order.Precision = market.Precision.Amount
+	o.order.Limit = market.Limits.Amount.Min
+	o.order.MakerFee = market.Maker
+	o.order.TakerFee = market.Taker
+
+	if market.Maker == 0 && market.Taker == 0 {
+		o.setDefaultFees()
+	}
+
+	return nil
+}
+
+func (o *Order) setDefaultFees() {
+	fee := OrderSpotFee
+	if o.order.Exchange == entities.ExchangeFutures {
+		fee = OrderFuturesFee
+	}
+
+	o.order.MakerFee = fee
+	o.order.TakerFee = fee
+}
+
 func (o *Order) Process(ctx context.Context) (<-chan entities.OrderStatus, error) {
 	switch o.order.Type {
 	case entities.OrderTypeMarket:
-		return NewMarketOrder(o.order).Process(ctx), nil
+		return NewMarketOrder(o.order, o.orderbook).Process(ctx), nil
 
 	case entities.OrderTypeLimit:
-		return NewLimitOrder(o.order).Process(ctx), nil
+		return NewLimitOrder(o.order, o.orderbook).Process(ctx), nil
+
+	case entities.OrderTypeStopMarket, entities.OrderTypeStopLimit:
+		return NewStopOrder(o.order, o.orderbook).Process(ctx), nil
+
+	case entities.OrderTypeTrailingStop:
+		return NewTrailingStopOrder(o.order, o.orderbook).Process(ctx), nil
 
 	default:
 		return nil, apperror.ErrOrderTypeNotValid
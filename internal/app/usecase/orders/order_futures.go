@@ -2,6 +2,8 @@ package orders
 
 import (
 	"context"
+	"fmt"
+	"math"
 
 	"DemoExchange/internal/app/apperror"
 	"DemoExchange/internal/app/entities"
@@ -58,3 +60,98 @@ func (o *OrderFutures) Validate() error {
 		return apperror.ErrOrderPositionModeIsNotValid
 	}
 }
+
+// checkMarginBracket rejects a leverage above the limit of the bracket the position notional falls into
+func checkMarginBracket(uc Usecase, symbol entities.Symbol, leverage entities.PositionLeverage, notional float64) error {
+	bracket := uc.GetMarginBracket(symbol, notional)
+	if bracket.MaxLeverage > 0 && leverage > bracket.MaxLeverage {
+		return apperror.ErrLeverageIsOutOfRange
+	}
+
+	return nil
+}
+
+// settleBalance credits the amount to the wallet of the order account or debits it when it is negative,
+// a debit above the wallet total is covered by the collateral coins and limited to what they raise
+func settleBalance(ctx context.Context, uc Usecase, log Logger, order *entities.Order, amount float64) error {
+	coin := order.Symbol.GetCoins().CoinBase
+
+	if amount >= 0 {
+		balance := entities.Balance{
+			Coin:  coin,
+			Total: amount,
+		}
+
+		err := uc.AppendBalance(ctx, order.Exchange, order.AccountUID, balance)
+		if err != nil {
+			log.Error(fmt.Sprintf("settleBalance:AppendBalance [AccountUID: %s, exchange: %s, balance: %+v] error: %v", order.AccountUID, order.Exchange, balance, err))
+			return err
+		}
+
+		return nil
+	}
+
+	total, _, err := uc.GetBalanceCoin(ctx, order.Exchange, order.AccountUID, coin)
+	if err != nil {
+		log.Error(fmt.Sprintf("settleBalance:GetBalanceCoin [%+v] error: %v", order, err))
+		return err
+	}
+
+	if shortfall := -amount - math.Max(total, 0); shortfall > 0 {
+		converted, err := uc.ConvertCollateral(ctx, order.Exchange, order.AccountUID, coin, shortfall)
+		if err != nil {
+			log.Error(fmt.Sprintf("settleBalance:ConvertCollateral [%+v] error: %v", order, err))
+			return err
+		}
+		total += converted
+	}
+
+	balance := entities.Balance{
+		Coin:  coin,
+		Total: math.Min(-amount, math.Max(total, 0)),
+	}
+
+	err = uc.SubtractBalance(ctx, order.Exchange, order.AccountUID, balance)
+	if err != nil {
+		log.Error(fmt.Sprintf("settleBalance:SubtractBalance [AccountUID: %s, exchange: %s, balance: %+v] error: %v", order.AccountUID, order.Exchange, balance, err))
+		return err
+	}
+
+	return nil
+}
+
+// coverMargin sells the collateral coins for the part of the cost above the wallet total of the order account
+// and returns the wallet total raised by the conversion
+func coverMargin(ctx context.Context, uc Usecase, log Logger, order *entities.Order, cost, total float64) (float64, error) {
+	shortfall := cost - math.Max(total, 0)
+	if shortfall <= 0 {
+		return total, nil
+	}
+
+	coin := order.Symbol.GetCoins().CoinBase
+
+	converted, err := uc.ConvertCollateral(ctx, order.Exchange, order.AccountUID, coin, shortfall)
+	if err != nil {
+		log.Error(fmt.Sprintf("coverMargin:ConvertCollateral [%+v] error: %v", order, err))
+		return total, err
+	}
+
+	return total + converted, nil
+}
+
+// appendRealizedPnl adds the pnl to the order and records it in the ledger linked to the order
+func appendRealizedPnl(ctx context.Context, uc Usecase, log Logger, order *entities.Order, pnl float64) error {
+	order.RealizedPnl += pnl
+
+	transaction := entities.NewTransaction(order.AccountUID, order.Exchange, order.Symbol, entities.TransactionTypeRealizedPnl, pnl)
+	transaction.OrderUID = order.OrderUID
+	transaction.Price = order.Price
+
+	err := uc.AppendTransaction(ctx, transaction)
+	if err != nil {
+		log.Error(fmt.Sprintf("appendRealizedPnl:AppendTransaction [%+v] error: %v", *transaction, err))
+		return err
+	}
+
+	return nil
+}
@@ -104,6 +104,10 @@ func (o *OrderFuturesHedgeClose) AppendBalance(ctx context.Context, uc Usecase,
 		return err
 	}
 
+	// the closed part is settled against the entry price before the position changes
+	pnl := position.RealizedPnl(o.order.Amount, o.order.Price)
+	margin := o.order.Amount * position.Price / position.Leverage.ToFloat64()
+
 	position.HoldAmount -= o.order.Amount
 	if position.HoldAmount < 0 {
 		position.HoldAmount = 0
@@ -126,33 +130,16 @@ func (o *OrderFuturesHedgeClose) AppendBalance(ctx context.Context, uc Usecase,
 
 	cost := o.order.Amount * o.order.Price
 
-	o.order.Fee = cost * OrderFuturesFee
+	o.order.Fee = cost * o.order.FeeRate()
 	o.order.FeeCoin = coin
 
-	leverage := o.order.Leverage.ToFloat64()
-
-	balance := entities.Balance{
-		Coin:  coin,
-		Total: cost/leverage - o.order.Fee,
-	}
-
-	err = uc.AppendBalance(ctx, o.order.Exchange, o.order.AccountUID, balance)
+	err = settleBalance(ctx, uc, log, o.order, margin+pnl-o.order.Fee)
 	if err != nil {
-		log.Error(fmt.Sprintf("AppendBalance:AppendBalance [AccountUID: %s, exchange: %s, balance: %+v, leverage: %v] error: %v", o.order.AccountUID, o.order.Exchange, balance, leverage, err))
 		return err
 	}
 
-	var pnl float64
-	if position.Side == entities.PositionSideLong {
-		pnl = o.order.Amount * (o.order.Price - position.Price)
-	} else {
-		pnl = o.order.Amount * (position.Price - o.order.Price)
-	}
-
-	transaction := entities.NewTransaction(o.order.AccountUID, o.order.Exchange, o.order.Symbol, entities.TransactionTypeRealizedPnl, pnl)
-	err = uc.AppendTransaction(ctx, transaction)
+	err = appendRealizedPnl(ctx, uc, log, o.order, pnl)
 	if err != nil {
-		log.Error(fmt.Sprintf("AppendBalance:AppendTransaction [%+v] error: %v", *transaction, err))
 		return err
 	}
 
@@ -51,18 +51,30 @@ func (o *OrderFuturesHedgeOpen) HoldBalance(ctx context.Context, uc Usecase, log
 
 	o.order.Leverage = position.Leverage
 
+	notional := (position.Amount + o.order.Amount) * o.order.Price
+	if err := checkMarginBracket(uc, o.order.Symbol, o.order.Leverage, notional); err != nil {
+		log.Error(fmt.Sprintf("HoldBalance:checkMarginBracket [%+v] error: %v", o, err))
+		return err
+	}
+
 	cost := o.order.Amount * o.order.Price
 
-	o.order.Fee = cost * OrderFuturesFee
+	o.order.Fee = cost * o.order.FeeRate()
 	o.order.FeeCoin = coin
 
 	leverage := o.order.Leverage.ToFloat64()
 
 	cost = cost/leverage + o.order.Fee
 
+	collateral, err := uc.GetCollateral(ctx, o.order.Exchange, o.order.AccountUID, coin)
+	if err != nil {
+		log.Error(fmt.Sprintf("HoldBalance:GetCollateral [%+v] error: %v", o, err))
+		return err
+	}
+
 	hold := balanceHold + cost
-	if hold > balanceTotal {
-		log.Error(fmt.Sprintf("HoldBalance:ErrInsufficientFunds [AccountUID: %s, exchange: %s, coin: %s, balance_total: %v, balance_hold: %v, cost: %v]", o.order.AccountUID, o.order.Exchange, coin, balanceTotal, balanceHold, cost))
+	if hold > balanceTotal+collateral {
+		log.Error(fmt.Sprintf("HoldBalance:ErrInsufficientFunds [AccountUID: %s, exchange: %s, coin: %s, balance_total: %v, balance_hold: %v, collateral: %v, cost: %v]", o.order.AccountUID, o.order.Exchange, coin, balanceTotal, balanceHold, collateral, cost))
 		return apperror.ErrInsufficientFunds
 	}
 
@@ -92,7 +104,7 @@ func (o *OrderFuturesHedgeOpen) UnholdBalance(ctx context.Context, uc Usecase, l
 
 	cost := o.order.Amount * o.order.Price
 
-	o.order.Fee = cost * OrderFuturesFee
+	o.order.Fee = cost * o.order.FeeRate()
 	o.order.FeeCoin = coin
 
 	leverage := o.order.Leverage.ToFloat64()
@@ -134,19 +146,27 @@ func (o *OrderFuturesHedgeOpen) AppendBalance(ctx context.Context, uc Usecase, l
 
 	cost := o.order.Amount * o.order.Price
 
-	o.order.Fee = cost * OrderFuturesFee
+	o.order.Fee = cost * o.order.FeeRate()
 	o.order.FeeCoin = coin
 
 	leverage := o.order.Leverage.ToFloat64()
 
 	cost = cost/leverage + o.order.Fee
 
+	balanceTotal, err = coverMargin(ctx, uc, log, o.order, cost, balanceTotal)
+	if err != nil {
+		return err
+	}
+
 	if cost > balanceTotal {
 		log.Error(fmt.Sprintf("AppendBalance:ErrInsufficientFunds [AccountUID: %s, coin: %s, balance_total: %v, cost: %v]", o.order.AccountUID, coin, balanceTotal, cost))
 		return apperror.ErrInsufficientFunds
 	}
 
-	unhold := balanceHold - cost
+	held := o.order.Amount * o.order.GetHoldPrice()
+	held = held/leverage + held*o.order.TakerFee
+
+	unhold := balanceHold - held
 	if unhold < 0 {
 		unhold = 0
 	}
@@ -175,12 +195,7 @@ func (o *OrderFuturesHedgeOpen) AppendBalance(ctx context.Context, uc Usecase, l
 		return err
 	}
 
-	if position.Amount == 0 {
-		position.Price = o.order.Price
-	} else {
-		position.Price = (position.Price + o.order.Price) / 2
-	}
-
+	position.AddEntry(o.order.Amount, o.order.Price)
 	position.Amount += o.order.Amount
 	position.Margin = position.Amount * position.Price / position.Leverage.ToFloat64()
 
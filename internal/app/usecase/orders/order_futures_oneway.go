@@ -81,6 +81,14 @@ func (o *OrderFuturesOneway) HoldBalance(ctx context.Context, uc Usecase, log Lo
 
 	hold := (o.order.Amount - holdPosition) * o.order.Price / leverage
 
+	if hold > 0 && !o.order.ReduceOnly {
+		notional := (math.Abs(position.Amount) - balancePosition + o.order.Amount - holdPosition) * o.order.Price
+		if err := checkMarginBracket(uc, o.order.Symbol, o.order.Leverage, notional); err != nil {
+			log.Error(fmt.Sprintf("HoldBalance:checkMarginBracket [%+v] error: %v", o, err))
+			return err
+		}
+	}
+
 	if o.order.ReduceOnly {
 		if balancePosition <= 0 {
 			log.Error(fmt.Sprintf("HoldBalance:ErrInsufficientFunds [AccountUID: %s, exchange: %s, coin: %s, balance_position: %v]", o.order.AccountUID, o.order.Exchange, coins.CoinQuote, balancePosition))
@@ -108,8 +116,14 @@ func (o *OrderFuturesOneway) HoldBalance(ctx context.Context, uc Usecase, log Lo
 			return err
 		}
 
-		if hold > balanceTotal-balanceHold {
-			log.Error(fmt.Sprintf("HoldBalance:ErrInsufficientFunds [AccountUID: %s, exchange: %s, coin: %s, balance_total: %v, balance_hold: %v, hold: %v]", o.order.AccountUID, o.order.Exchange, coin, balanceTotal, balanceHold, hold))
+		collateral, err := uc.GetCollateral(ctx, o.order.Exchange, o.order.AccountUID, coin)
+		if err != nil {
+			log.Error(fmt.Sprintf("HoldBalance:GetCollateral [%+v] error: %v", o, err))
+			return err
+		}
+
+		if hold > balanceTotal-balanceHold+collateral {
+			log.Error(fmt.Sprintf("HoldBalance:ErrInsufficientFunds [AccountUID: %s, exchange: %s, coin: %s, balance_total: %v, balance_hold: %v, collateral: %v, hold: %v]", o.order.AccountUID, o.order.Exchange, coin, balanceTotal, balanceHold, collateral, hold))
 			return apperror.ErrInsufficientFunds
 		}
 
@@ -198,9 +212,10 @@ func (o *OrderFuturesOneway) AppendBalance(ctx context.Context, uc Usecase, log
 		return err
 	}
 
-	unhold := o.order.Amount
-	if unhold > position.HoldAmount {
-		unhold = position.HoldAmount
+	// only an order against the position releases the amount held from it
+	var unhold float64
+	if o.order.Side == entities.OrderSideBuy && position.Amount < 0 || o.order.Side == entities.OrderSideSell && position.Amount > 0 {
+		unhold = math.Min(o.order.Amount, position.HoldAmount)
 	}
 
 	position.HoldAmount -= unhold
@@ -221,21 +236,60 @@ func (o *OrderFuturesOneway) AppendBalance(ctx context.Context, uc Usecase, log
 
 	leverage := o.order.Leverage.ToFloat64()
 
-	if o.order.Amount > 0 {
-		if o.order.Amount > unhold {
-			if position.Amount == 0 {
-				position.Price = o.order.Price
-			} else {
-				position.Price = (position.Price + o.order.Price) / 2
-			}
+	// the closed part is settled against the entry price before the position changes
+	pnl := position.RealizedPnl(unhold, o.order.Price)
+	margin := unhold * position.Price / position.Leverage.ToFloat64()
+
+	var closeFee float64
+	if unhold > 0 {
+		closeFee = unhold * o.order.Price * o.order.FeeRate()
+	}
+
+	// the opened part and its fee on the notional are paid before the position changes,
+	// the collateral covers what the wallet lacks
+	open := o.order.Amount - unhold
+	openFee := open * o.order.Price * o.order.FeeRate()
+	cost := open*o.order.Price/leverage + openFee
+
+	var balanceHold float64
+	if open > 0 {
+		var balanceTotal float64
+		balanceTotal, balanceHold, err = uc.GetBalanceCoin(ctx, o.order.Exchange, o.order.AccountUID, coin)
+		if err != nil {
+			log.Error(fmt.Sprintf("AppendBalance:GetBalanceCoin [%+v] error: %v", o, err))
+			return err
 		}
 
+		// the closed part of a reversing order is credited before the opened part is paid
+		balanceTotal += margin + pnl - closeFee
+
+		balanceTotal, err = coverMargin(ctx, uc, log, o.order, cost, balanceTotal)
+		if err != nil {
+			return err
+		}
+
+		if cost > balanceTotal {
+			log.Error(fmt.Sprintf("AppendBalance:ErrInsufficientFunds [AccountUID: %s, coin: %s, balance_total: %v, cost: %v]", o.order.AccountUID, coin, balanceTotal, cost))
+			return apperror.ErrInsufficientFunds
+		}
+	}
+
+	if o.order.Amount > 0 {
+		amount := position.Amount
 		if o.order.Side == entities.OrderSideBuy {
-			position.Amount += o.order.Amount
+			amount += o.order.Amount
 		} else {
-			position.Amount -= o.order.Amount
+			amount -= o.order.Amount
 		}
 
+		switch {
+		case position.Amount == 0 || amount != 0 && (amount > 0) != (position.Amount > 0):
+			position.Price = o.order.Price
+		case math.Abs(amount) > math.Abs(position.Amount):
+			position.AddEntry(math.Abs(amount)-math.Abs(position.Amount), o.order.Price)
+		}
+
+		position.Amount = amount
 		position.Margin = math.Abs(position.Amount) * position.Price / position.Leverage.ToFloat64()
 		position.UpdateTS = o.order.UpdateTS
 
@@ -247,52 +301,28 @@ func (o *OrderFuturesOneway) AppendBalance(ctx context.Context, uc Usecase, log
 	}
 
 	if unhold > 0 {
-		cost := unhold * o.order.Price
-
-		o.order.Fee = cost * OrderFuturesFee
+		o.order.Fee = closeFee
 		o.order.FeeCoin = coin
 
-		balance := entities.Balance{
-			Coin:  coin,
-			Total: cost/leverage - o.order.Fee,
-		}
-
-		err = uc.AppendBalance(ctx, o.order.Exchange, o.order.AccountUID, balance)
+		err = settleBalance(ctx, uc, log, o.order, margin+pnl-o.order.Fee)
 		if err != nil {
-			log.Error(fmt.Sprintf("AppendBalance:AppendBalance [AccountUID: %s, exchange: %s, balance: %+v, leverage: %v] error: %v", o.order.AccountUID, o.order.Exchange, balance, leverage, err))
 			return err
 		}
 
-		var pnl float64
-		if position.Side == entities.PositionSideLong {
-			pnl = o.order.Amount * (o.order.Price - position.Price)
-		} else {
-			pnl = o.order.Amount * (position.Price - o.order.Price)
-		}
-
-		transaction := entities.NewTransaction(o.order.AccountUID, o.order.Exchange, o.order.Symbol, entities.TransactionTypeRealizedPnl, pnl)
-		err = uc.AppendTransaction(ctx, transaction)
+		err = appendRealizedPnl(ctx, uc, log, o.order, pnl)
 		if err != nil {
-			log.Error(fmt.Sprintf("AppendBalance:AppendTransaction [%+v] error: %v", *transaction, err))
 			return err
 		}
 	}
 
-	o.order.Amount -= unhold
+	o.order.Amount = open
 	if o.order.Amount > 0 {
-		balanceTotal, balanceHold, err := uc.GetBalanceCoin(ctx, o.order.Exchange, o.order.AccountUID, coin)
-		if err != nil {
-			log.Error(fmt.Sprintf("AppendBalance:GetBalanceCoin [%+v] error: %v", o, err))
-			return err
-		}
-
-		cost := o.order.Amount * o.order.Price / leverage
-		hold := balanceHold - cost
+		hold := balanceHold - o.order.Amount*o.order.GetHoldPrice()/leverage
 		if hold < 0 {
 			hold = 0
 		}
 
-		o.order.Fee = cost * OrderFuturesFee
+		o.order.Fee = closeFee + openFee
 		o.order.FeeCoin = coin
 
 		balance := entities.Balance{
@@ -307,11 +337,6 @@ func (o *OrderFuturesOneway) AppendBalance(ctx context.Context, uc Usecase, log
 			return err
 		}
 
-		if cost > balanceTotal {
-			log.Error(fmt.Sprintf("AppendBalance:ErrInsufficientFunds [AccountUID: %s, coin: %s, balance_total: %v, cost: %v]", o.order.AccountUID, coin, balanceTotal, cost))
-			return apperror.ErrInsufficientFunds
-		}
-
 		err = uc.SubtractBalance(ctx, o.order.Exchange, o.order.AccountUID, balance)
 		if err != nil {
 			log.Error(fmt.Sprintf("AppendBalance:SubtractBalance [AccountUID: %s, exchange: %s, balance: %+v] error: %v", o.order.AccountUID, o.order.Exchange, balance, err))
@@ -26,7 +26,7 @@ func (o *OrderSpotBuy) HoldBalance(ctx context.Context, uc Usecase, log Logger)
 		return err
 	}
 
-	o.order.Fee = o.order.Amount * OrderSpotFee
+	o.order.Fee = o.order.Amount * o.order.FeeRate()
 	o.order.FeeCoin = coins.CoinQuote
 
 	cost := (o.order.Amount + o.order.Fee) * o.order.Price
@@ -60,7 +60,7 @@ func (o *OrderSpotBuy) UnholdBalance(ctx context.Context, uc Usecase, log Logger
 		return err
 	}
 
-	o.order.Fee = o.order.Amount * OrderSpotFee
+	o.order.Fee = o.order.Amount * o.order.FeeRate()
 	o.order.FeeCoin = coins.CoinQuote
 
 	cost := (o.order.Amount + o.order.Fee) * o.order.Price
@@ -98,7 +98,7 @@ func (o *OrderSpotBuy) AppendBalance(ctx context.Context, uc Usecase, log Logger
 		return err
 	}
 
-	o.order.Fee = o.order.Amount * OrderSpotFee
+	o.order.Fee = o.order.Amount * o.order.FeeRate()
 	o.order.FeeCoin = coins.CoinQuote
 
 	cost := (o.order.Amount + o.order.Fee) * o.order.Price
@@ -107,7 +107,10 @@ func (o *OrderSpotBuy) AppendBalance(ctx context.Context, uc Usecase, log Logger
 		return apperror.ErrInsufficientFunds
 	}
 
-	hold := balanceHold - cost
+	// balance is held with the taker fee
+	held := (o.order.Amount + o.order.Amount*o.order.TakerFee) * o.order.GetHoldPrice()
+
+	hold := balanceHold - held
 	if hold < 0 {
 		hold = 0
 	}
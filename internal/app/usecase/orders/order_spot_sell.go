@@ -120,7 +120,7 @@ func (o *OrderSpotSell) AppendBalance(ctx context.Context, uc Usecase, log Logge
 
 	cost := o.order.Amount * o.order.Price
 
-	o.order.Fee = cost * OrderSpotFee
+	o.order.Fee = cost * o.order.FeeRate()
 	o.order.FeeCoin = coins.CoinBase
 
 	appendBalance := entities.Balance{
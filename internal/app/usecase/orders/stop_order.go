@@ -0,0 +1,137 @@
+package orders
+
+import (
+	"context"
+	"time"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/entities"
+	"DemoExchange/internal/app/tickers"
+)
+
+type StopOrder struct {
+	order     *entities.Order
+	tickers   Tickers
+	orderbook Orderbook
+}
+
+func NewStopOrder(o *entities.Order, orderbook Orderbook) *StopOrder {
+	return &StopOrder{
+		order:     o,
+		tickers:   tickers.New(),
+		orderbook: orderbook,
+	}
+}
+
+func (o *StopOrder) Validate() error {
+	if o.order.StopPrice <= 0 {
+		return apperror.ErrStopPriceIsNotValid
+	}
+
+	if o.order.Type == entities.OrderTypeStopLimit {
+		if o.order.Price <= 0 {
+			return apperror.ErrPriceIsNotValid
+		}
+	} else {
+		// balance is held at the stop price until the order is triggered
+		o.order.Price = o.order.StopPrice
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ticker, err := o.tickers.GetTickerWithContext(ctx, o.order.Exchange.Name(), o.order.Symbol.String())
+	if err != nil {
+		return apperror.ErrMarketPriceIsWrong
+	}
+
+	if o.isTriggered(ticker.Last) {
+		return apperror.ErrStopPriceWouldTrigger
+	}
+
+	return nil
+}
+
+func (o *StopOrder) isTriggered(price float64) bool {
+	if o.order.Side == entities.OrderSideBuy {
+		return price >= o.order.StopPrice
+	}
+
+	return price <= o.order.StopPrice
+}
+
+func (o *StopOrder) Process(ctx context.Context) <-chan entities.OrderStatus {
+	ch := make(chan entities.OrderStatus)
+
+	go func() {
+		defer close(ch)
+
+		o.order.Status = entities.OrderStatusPending
+		ch <- entities.OrderStatusPending
+
+		remaining := o.order.RemainingAmount()
+
+		var (
+			ticker tickers.Ticker
+			err    error
+		)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				status := o.order.Status
+				if status.IsFinal() {
+					ch <- status
+					return
+				}
+
+				ticker, err = o.tickers.GetTickerWithContext(ctx, o.order.Exchange.Name(), o.order.Symbol.String())
+				if err != nil {
+					time.Sleep(processTimeout)
+					continue
+				}
+
+				status = o.order.Status
+				if status.IsFinal() {
+					ch <- status
+					return
+				}
+
+				if !o.order.Triggered {
+					if !o.isTriggered(ticker.Last) {
+						time.Sleep(processTimeout)
+						continue
+					}
+
+					// pending is sent again to persist the trigger state
+					o.order.Triggered = true
+					ch <- entities.OrderStatusPending
+				}
+
+				if o.order.Type == entities.OrderTypeStopMarket {
+					sendSweep(ctx, ch, o.orderbook, o.order, ticker.Last)
+					return
+				}
+
+				if crossesLimit(o.order, ticker.Last) {
+					fill := crossedAmount(ctx, o.orderbook, o.order, remaining)
+					o.order.Trade = entities.NewTrade(o.order, fill, o.order.Price, true)
+
+					remaining -= fill
+					if remaining <= 0 {
+						ch <- entities.OrderStatusSuccess
+						return
+					}
+
+					ch <- entities.OrderStatusPartiallyFilled
+				}
+
+				time.Sleep(processTimeout)
+			}
+		}
+	}()
+
+	return ch
+}
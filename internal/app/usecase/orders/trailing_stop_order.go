@@ -0,0 +1,153 @@
+package orders
+
+import (
+	"context"
+	"time"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/entities"
+	"DemoExchange/internal/app/tickers"
+)
+
+const (
+	minCallbackRate = 0.1
+	maxCallbackRate = 10
+)
+
+type TrailingStopOrder struct {
+	order     *entities.Order
+	tickers   Tickers
+	orderbook Orderbook
+}
+
+func NewTrailingStopOrder(o *entities.Order, orderbook Orderbook) *TrailingStopOrder {
+	return &TrailingStopOrder{
+		order:     o,
+		tickers:   tickers.New(),
+		orderbook: orderbook,
+	}
+}
+
+func (o *TrailingStopOrder) Validate() error {
+	if o.order.CallbackRate < minCallbackRate || o.order.CallbackRate > maxCallbackRate {
+		return apperror.ErrCallbackRateIsNotValid
+	}
+
+	if o.order.ActivationPrice < 0 {
+		return apperror.ErrPriceIsNotValid
+	}
+
+	// trailing level is set on activation
+	o.order.StopPrice = 0
+
+	if o.order.ActivationPrice > 0 {
+		// balance is held at the activation price until the order is triggered
+		o.order.Price = o.order.ActivationPrice
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ticker, err := o.tickers.GetTickerWithContext(ctx, o.order.Exchange.Name(), o.order.Symbol.String())
+	if err != nil {
+		return apperror.ErrMarketPriceIsWrong
+	}
+
+	o.order.Price = ticker.Last
+
+	return nil
+}
+
+func (o *TrailingStopOrder) isActivated(price float64) bool {
+	if o.order.ActivationPrice == 0 {
+		return true
+	}
+
+	if o.order.Side == entities.OrderSideBuy {
+		return price <= o.order.ActivationPrice
+	}
+
+	return price >= o.order.ActivationPrice
+}
+
+func (o *TrailingStopOrder) isTriggered(price float64) bool {
+	if o.order.Side == entities.OrderSideBuy {
+		return price >= o.order.StopPrice
+	}
+
+	return price <= o.order.StopPrice
+}
+
+// trailingLevel returns the trigger level for the price, it moves only in favor of the order
+func (o *TrailingStopOrder) trailingLevel(price float64) (float64, bool) {
+	if o.order.Side == entities.OrderSideBuy {
+		level := price * (1 + o.order.CallbackRate/100)
+		return level, o.order.StopPrice == 0 || level < o.order.StopPrice
+	}
+
+	level := price * (1 - o.order.CallbackRate/100)
+	return level, o.order.StopPrice == 0 || level > o.order.StopPrice
+}
+
+func (o *TrailingStopOrder) Process(ctx context.Context) <-chan entities.OrderStatus {
+	ch := make(chan entities.OrderStatus)
+
+	go func() {
+		defer close(ch)
+
+		o.order.Status = entities.OrderStatusPending
+		ch <- entities.OrderStatusPending
+
+		var (
+			ticker tickers.Ticker
+			err    error
+		)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				status := o.order.Status
+				if status.IsFinal() {
+					ch <- status
+					return
+				}
+
+				ticker, err = o.tickers.GetTickerWithContext(ctx, o.order.Exchange.Name(), o.order.Symbol.String())
+				if err != nil {
+					time.Sleep(processTimeout)
+					continue
+				}
+
+				status = o.order.Status
+				if status.IsFinal() {
+					ch <- status
+					return
+				}
+
+				if !o.order.Triggered && !o.isActivated(ticker.Last) {
+					time.Sleep(processTimeout)
+					continue
+				}
+
+				if o.order.Triggered && o.isTriggered(ticker.Last) {
+					sendSweep(ctx, ch, o.orderbook, o.order, ticker.Last)
+					return
+				}
+
+				// pending is sent again to persist the trailing level
+				if level, ok := o.trailingLevel(ticker.Last); ok {
+					o.order.StopPrice = level
+					o.order.Triggered = true
+					ch <- entities.OrderStatusPending
+				}
+
+				time.Sleep(processTimeout)
+			}
+		}
+	}()
+
+	return ch
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -59,8 +60,7 @@ func (uc *Usecase) PositionsList(ctx context.Context, exchange entities.Exchange
 
 	for _, ticker := range tickers {
 		symbol := entities.Symbol(ticker.Symbol)
-		coins := symbol.GetCoins()
-		balance := balances[coins.CoinBase].WalletBalance
+		brackets := uc.marginBrackets(symbol)
 
 		if account.PositionMode == entities.PositionModeOneway {
 			position, ok := mapPositions[key{symbol: symbol, side: entities.PositionSideBoth}]
@@ -68,8 +68,8 @@ func (uc *Usecase) PositionsList(ctx context.Context, exchange entities.Exchange
 				position = entities.NewPosition(account, exchange, symbol, entities.PositionSideBoth)
 			}
 
-			position.CalcMarginBalance(ticker.Last)
-			position.CalcLiquidationPrice(balance)
+			position.CalcMarginBalance(markPrice(ticker))
+			position.CalcMaintMargin(brackets)
 			result = append(result, position)
 		} else {
 			positionLong, ok := mapPositions[key{symbol: symbol, side: entities.PositionSideLong}]
@@ -77,8 +77,8 @@ func (uc *Usecase) PositionsList(ctx context.Context, exchange entities.Exchange
 				positionLong = entities.NewPosition(account, exchange, symbol, entities.PositionSideLong)
 			}
 
-			positionLong.CalcMarginBalance(ticker.Last)
-			positionLong.CalcLiquidationPrice(balance)
+			positionLong.CalcMarginBalance(markPrice(ticker))
+			positionLong.CalcMaintMargin(brackets)
 			result = append(result, positionLong)
 
 			positionShort, ok := mapPositions[key{symbol: symbol, side: entities.PositionSideShort}]
@@ -86,12 +86,39 @@ func (uc *Usecase) PositionsList(ctx context.Context, exchange entities.Exchange
 				positionShort = entities.NewPosition(account, exchange, symbol, entities.PositionSideShort)
 			}
 
-			positionShort.CalcMarginBalance(ticker.Last)
-			positionShort.CalcLiquidationPrice(balance)
+			positionShort.CalcMarginBalance(markPrice(ticker))
+			positionShort.CalcMaintMargin(brackets)
 			result = append(result, positionShort)
 		}
 	}
 
+	crossPositions := make(map[entities.Coin][]*entities.Position)
+	for _, position := range result {
+		if position.Amount != 0 && position.MarginType == entities.MarginTypeCross {
+			coin := position.Symbol.GetCoins().CoinBase
+			crossPositions[coin] = append(crossPositions[coin], position)
+		}
+	}
+
+	crossMargins := make(map[entities.Coin]entities.CrossMargin, len(crossPositions))
+	for coin, positions := range crossPositions {
+		crossMargins[coin] = entities.NewCrossMargin(balances[coin].Total, positions)
+	}
+
+	for _, position := range result {
+		var balance float64
+		if position.MarginType == entities.MarginTypeCross {
+			balance = crossMargins[position.Symbol.GetCoins().CoinBase].Balance(position)
+		}
+
+		position.CalcLiquidationPrice(balance, uc.marginBrackets(position.Symbol))
+	}
+
+	if err := uc.setADLRanks(ctx, exchange, result); err != nil {
+		uc.log.Error(fmt.Sprintf("PositionsList:setADLRanks [AccountUID: %s] error: %v", accountUID, err))
+		return nil, err
+	}
+
 	return result, nil
 }
 
@@ -124,6 +151,10 @@ func (uc *Usecase) SetPositionMarginType(ctx context.Context, exchange entities.
 }
 
 func (uc *Usecase) SetPositionLeverage(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol entities.Symbol, leverage entities.PositionLeverage) error {
+	if bracket := uc.GetMarginBracket(symbol, 0); bracket.MaxLeverage > 0 && leverage > bracket.MaxLeverage {
+		return apperror.ErrSetLeverage.Wrap(apperror.ErrLeverageIsOutOfRange)
+	}
+
 	return uc.position.WithTx(ctx, func(ctx context.Context) error {
 		if err := uc.checkPresentPendingOrders(ctx, exchange, accountUID, &symbol); err != nil {
 			return apperror.ErrSetLeverage.Wrap(err)
@@ -151,6 +182,61 @@ func (uc *Usecase) SetPositionLeverage(ctx context.Context, exchange entities.Ex
 	})
 }
 
+func (uc *Usecase) SetPositionTPSL(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol entities.Symbol, side entities.PositionSide, takeProfit, stopLoss float64) error {
+	if exchange != entities.ExchangeFutures {
+		return apperror.ErrSetTPSL.Wrap(apperror.ErrExchangeIsNotValid)
+	}
+
+	if takeProfit < 0 || stopLoss < 0 {
+		return apperror.ErrSetTPSL.Wrap(apperror.ErrPriceIsNotValid)
+	}
+
+	return uc.position.WithTx(ctx, func(ctx context.Context) error {
+		account, err := uc.GetAccountByUID(ctx, accountUID)
+		if err != nil {
+			uc.log.Error(fmt.Sprintf("SetPositionTPSL:GetAccountByUID [AccountUID: %s] error: %v", accountUID, err))
+			return apperror.ErrSetTPSL.Wrap(err)
+		}
+
+		if side == "" && account.PositionMode == entities.PositionModeOneway {
+			side = entities.PositionSideBoth
+		}
+
+		if side.PositionMode() != account.PositionMode {
+			return apperror.ErrSetTPSL.Wrap(apperror.ErrInvalidPositionSide)
+		}
+
+		position, err := uc.position.SelectPositionBySide(ctx, accountUID, symbol, side)
+		if err != nil {
+			return apperror.ErrSetTPSL.Wrap(err)
+		}
+
+		if position.Amount == 0 {
+			return apperror.ErrSetTPSL.Wrap(apperror.ErrPositionNotFound)
+		}
+
+		ticker, err := uc.tickers.GetTickerWithContext(ctx, exchange.Name(), symbol.String())
+		if err != nil {
+			return apperror.ErrSetTPSL.Wrap(apperror.ErrMarketPriceIsWrong)
+		}
+
+		position.TakeProfit = takeProfit
+		position.StopLoss = stopLoss
+
+		if position.CheckTPSL(ticker.Last) {
+			return apperror.ErrSetTPSL.Wrap(apperror.ErrStopPriceWouldTrigger)
+		}
+
+		position.UpdateTS = entities.TS()
+
+		if err := uc.SavePosition(ctx, position); err != nil {
+			return apperror.ErrSetTPSL.Wrap(err)
+		}
+
+		return nil
+	})
+}
+
 func (uc *Usecase) getPositionsBySymbol(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol entities.Symbol) (map[entities.PositionSide]*entities.Position, error) {
 	account, err := uc.GetAccountByUID(ctx, accountUID)
 	if err != nil {
@@ -219,6 +305,11 @@ func (uc *Usecase) GetPositionBySide(ctx context.Context, exchange entities.Exch
 }
 
 func (uc *Usecase) SavePosition(ctx context.Context, position *entities.Position) error {
+	if position.Amount == 0 {
+		position.TakeProfit = 0
+		position.StopLoss = 0
+	}
+
 	if position.IsNew {
 		if err := uc.position.InsertPosition(ctx, position); err != nil {
 			uc.log.Error(fmt.Sprintf("savePosition:InsertPosition [%+v] error: %v", *position, err))
@@ -270,6 +361,7 @@ func (uc *Usecase) ProcessPositions(ctx context.Context) {
 
 		case position := <-uc.chPositions:
 			uc.log.Info(fmt.Sprintf("ProcessPositions [%+v]", *position))
+			uc.publishPosition(position)
 
 			go func(position *entities.Position) {
 				muPositionProcess.Lock()
@@ -279,6 +371,8 @@ func (uc *Usecase) ProcessPositions(ctx context.Context) {
 					old.Price = position.Price
 					old.Margin = position.Margin
 					old.HoldAmount = position.HoldAmount
+					old.TakeProfit = position.TakeProfit
+					old.StopLoss = position.StopLoss
 					old.UpdateTS = position.UpdateTS
 					muPositionProcess.Unlock()
 					return
@@ -309,6 +403,10 @@ func (uc *Usecase) ProcessPositions(ctx context.Context) {
 							return
 						}
 
+						if uc.checkPositionTPSL(ctx, position) {
+							uc.log.Info(fmt.Sprintf("ProcessPositions:checkPositionTPSL [%+v]", *position))
+						}
+
 						muPositionProcess.Unlock()
 						time.Sleep(processTimeout)
 					}
@@ -338,35 +436,51 @@ func (uc *Usecase) ProcessOpenPositions(ctx context.Context) error {
 	return nil
 }
 
-func (uc *Usecase) checkPositionLiquidation(ctx context.Context, position *entities.Position) bool {
+func (uc *Usecase) checkPositionTPSL(ctx context.Context, position *entities.Position) bool {
+	if position.TakeProfit == 0 && position.StopLoss == 0 {
+		return false
+	}
+
 	ticker, err := uc.tickers.GetTickerWithContext(ctx, position.Exchange.Name(), position.Symbol.String())
 	if err != nil {
 		return false
 	}
 
-	position.CalcMarginBalance(ticker.Last)
+	if !position.CheckTPSL(ticker.Last) {
+		return false
+	}
 
-	if position.MarginBalance <= 0 {
-		err := uc.position.WithTx(ctx, func(ctx context.Context) error {
-			position.Amount = 0
-			position.HoldAmount = 0
-			position.Margin = 0
-			if err := uc.updatePosition(ctx, position); err != nil {
-				uc.log.Error(fmt.Sprintf("checkPositionLiquidation:updatePosition [%+v] error: %v", *position, err))
-				return err
-			}
+	order := entities.NewOrder(position.AccountUID)
+	order.Exchange = position.Exchange
+	order.Symbol = position.Symbol
+	order.Type = entities.OrderTypeMarket
+	order.PositionSide = position.Side
+	order.Side = position.CloseSide()
+	order.Amount = math.Abs(position.Amount)
+	order.ReduceOnly = true
+
+	// the targets are cleared before the order so they are not triggered twice, and restored when it fails
+	takeProfit, stopLoss := position.TakeProfit, position.StopLoss
+
+	position.TakeProfit = 0
+	position.StopLoss = 0
+	if err := uc.updatePosition(ctx, position); err != nil {
+		uc.log.Error(fmt.Sprintf("checkPositionTPSL:updatePosition [%+v] error: %v", *position, err))
+		return false
+	}
 
-			transaction := entities.NewTransaction(position.AccountUID, position.Exchange, position.Symbol, entities.TransactionTypeLiquidation, position.Amount)
-			if err := uc.AppendTransaction(ctx, transaction); err != nil {
-				uc.log.Error(fmt.Sprintf("checkPositionLiquidation:AppendTransaction [%+v] error: %v", *transaction, err))
-				return err
-			}
+	// the realized pnl of the close is recorded in the ledger when the order settles the closed part of the position
+	if err := uc.NewOrder(ctx, order); err != nil {
+		uc.log.Error(fmt.Sprintf("checkPositionTPSL:NewOrder [%+v] error: %v", *order, err))
 
-			return nil
-		})
+		position.TakeProfit = takeProfit
+		position.StopLoss = stopLoss
+		if err := uc.updatePosition(ctx, position); err != nil {
+			uc.log.Error(fmt.Sprintf("checkPositionTPSL:updatePosition [%+v] error: %v", *position, err))
+		}
 
-		return err == nil
+		return false
 	}
 
-	return false
+	return true
 }
@@ -32,10 +32,10 @@ func (s *Storage) WithTx(ctx context.Context, fn func(ctx context.Context) error
 
 func (s *Storage) InsertAccount(ctx context.Context, account *entities.Account) error {
 	sql := `
-		INSERT INTO account (account_uid, service, user_id, position_mode, create_ts, update_ts) VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO account (account_uid, service, user_id, position_mode, fee_tier, fee_tier_pinned, master_uid, label, create_ts, update_ts) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
-	return s.repo.Exec(ctx, sql, account.AccountUID, account.Service, account.UserID, account.PositionMode, account.CreateTS, account.UpdateTS)
+	return s.repo.Exec(ctx, sql, account.AccountUID, account.Service, account.UserID, account.PositionMode, account.FeeTier, account.FeeTierPinned, account.MasterUID, account.Label, account.CreateTS, account.UpdateTS)
 }
 
 func (s *Storage) UpdatePositionMode(ctx context.Context, account *entities.Account) error {
@@ -44,16 +44,22 @@ func (s *Storage) UpdatePositionMode(ctx context.Context, account *entities.Acco
 	return s.repo.Exec(ctx, sql, account.AccountUID, account.PositionMode, account.UpdateTS)
 }
 
+func (s *Storage) UpdateFeeTier(ctx context.Context, account *entities.Account) error {
+	sql := `UPDATE account SET fee_tier = $2, fee_tier_pinned = $3, update_ts = $4 WHERE account_uid = $1`
+
+	return s.repo.Exec(ctx, sql, account.AccountUID, account.FeeTier, account.FeeTierPinned, account.UpdateTS)
+}
+
 func (s *Storage) SelectAccount(ctx context.Context, service, userID string) (*entities.Account, error) {
 	var account entities.Account
 
 	sql := `
-		SELECT account_uid, service, user_id, position_mode, create_ts, update_ts FROM account WHERE service = $1 AND user_id = $2
+		SELECT account_uid, service, user_id, position_mode, fee_tier, fee_tier_pinned, master_uid, label, create_ts, update_ts FROM account WHERE service = $1 AND user_id = $2 AND master_uid = ''
 	`
 
 	row := s.repo.QueryRow(ctx, sql, service, userID)
 
-	err := row.Scan(&account.AccountUID, &account.Service, &account.UserID, &account.PositionMode, &account.CreateTS, &account.UpdateTS)
+	err := row.Scan(&account.AccountUID, &account.Service, &account.UserID, &account.PositionMode, &account.FeeTier, &account.FeeTierPinned, &account.MasterUID, &account.Label, &account.CreateTS, &account.UpdateTS)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, apperror.ErrAccountNotFound
@@ -68,12 +74,12 @@ func (s *Storage) SelectAccountByUID(ctx context.Context, accountUID entities.Ac
 	var account entities.Account
 
 	sql := `
-		SELECT account_uid, service, user_id, position_mode, create_ts, update_ts FROM account WHERE account_uid = $1
+		SELECT account_uid, service, user_id, position_mode, fee_tier, fee_tier_pinned, master_uid, label, create_ts, update_ts FROM account WHERE account_uid = $1
 	`
 
 	row := s.repo.QueryRow(ctx, sql, accountUID)
 
-	err := row.Scan(&account.AccountUID, &account.Service, &account.UserID, &account.PositionMode, &account.CreateTS, &account.UpdateTS)
+	err := row.Scan(&account.AccountUID, &account.Service, &account.UserID, &account.PositionMode, &account.FeeTier, &account.FeeTierPinned, &account.MasterUID, &account.Label, &account.CreateTS, &account.UpdateTS)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, apperror.ErrAccountNotFound
@@ -83,3 +89,30 @@ func (s *Storage) SelectAccountByUID(ctx context.Context, accountUID entities.Ac
 
 	return &account, nil
 }
+
+func (s *Storage) SelectSubAccounts(ctx context.Context, masterUID entities.AccountUID) ([]*entities.Account, error) {
+	sql := `
+		SELECT account_uid, service, user_id, position_mode, fee_tier, fee_tier_pinned, master_uid, label, create_ts, update_ts FROM account WHERE master_uid = $1 ORDER BY create_ts
+	`
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	rows, err = s.repo.Query(ctx, sql, masterUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var account entities.Account
+
+	accounts := make([]*entities.Account, 0)
+
+	_, err = pgx.ForEachRow(rows, []any{&account.AccountUID, &account.Service, &account.UserID, &account.PositionMode, &account.FeeTier, &account.FeeTierPinned, &account.MasterUID, &account.Label, &account.CreateTS, &account.UpdateTS}, func() error {
+		account := account
+		accounts = append(accounts, &account)
+		return nil
+	})
+
+	return accounts, err
+}
@@ -32,10 +32,10 @@ func (s *Storage) WithTx(ctx context.Context, fn func(ctx context.Context) error
 
 func (s *Storage) InsertAccountKey(ctx context.Context, key *entities.Key) error {
 	sql := `
-		INSERT INTO apikey (token, account_uid, create_ts, update_ts) VALUES ($1, $2, $3, $4)
+		INSERT INTO apikey (token, account_uid, secret, label, scopes, expire_ts, ip_whitelist, create_ts, update_ts) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	return s.repo.Exec(ctx, sql, key.Token, key.AccountUID, key.CreateTS, key.UpdateTS)
+	return s.repo.Exec(ctx, sql, key.Token, key.AccountUID, key.Secret, key.Label, scopesToStrings(key.Scopes), key.ExpireTS, key.IPWhitelist, key.CreateTS, key.UpdateTS)
 }
 
 func (s *Storage) UpdateAccountKey(ctx context.Context, key *entities.Key) error {
@@ -44,10 +44,29 @@ func (s *Storage) UpdateAccountKey(ctx context.Context, key *entities.Key) error
 	return s.repo.Exec(ctx, sql, key.Token, key.Disabled, key.UpdateTS)
 }
 
+// UpdateAccountKeySettings saves the editable settings of the key
+func (s *Storage) UpdateAccountKeySettings(ctx context.Context, key *entities.Key) error {
+	sql := `UPDATE apikey SET label = $2, scopes = $3, expire_ts = $4, ip_whitelist = $5, update_ts = $6 WHERE token = $1`
+
+	return s.repo.Exec(ctx, sql, key.Token, key.Label, scopesToStrings(key.Scopes), key.ExpireTS, key.IPWhitelist, key.UpdateTS)
+}
+
+// UpdateAccountKeySecret saves the new secret of the key, the key is no longer accepted unsigned
+func (s *Storage) UpdateAccountKeySecret(ctx context.Context, key *entities.Key) error {
+	sql := `UPDATE apikey SET secret = $2, unsigned = false, update_ts = $3 WHERE token = $1`
+
+	return s.repo.Exec(ctx, sql, key.Token, key.Secret, key.UpdateTS)
+}
+
 func (s *Storage) SelectAccountKeys(ctx context.Context, accountUID entities.AccountUID) ([]entities.Key, error) {
 	result := make([]entities.Key, 0)
 
-	sql := `SELECT token, create_ts, update_ts FROM apikey WHERE account_uid = $1 AND disabled = false`
+	sql := `
+		SELECT token, unsigned, label, scopes, expire_ts, ip_whitelist, create_ts, COALESCE(update_ts, create_ts) 
+		FROM apikey 
+		WHERE account_uid = $1 AND disabled = false
+		ORDER BY create_ts
+	`
 
 	rows, err := s.repo.Query(ctx, sql, accountUID)
 	if err != nil {
@@ -55,38 +74,61 @@ func (s *Storage) SelectAccountKeys(ctx context.Context, accountUID entities.Acc
 	}
 
 	var (
-		token    entities.Token
-		createTS int64
-		updateTS int64
+		key    entities.Key
+		scopes []string
 	)
 
-	_, err = pgx.ForEachRow(rows, []any{&token, &createTS, &updateTS}, func() error {
-		result = append(result, entities.Key{
-			Token:      token,
-			AccountUID: accountUID,
-			CreateTS:   createTS,
-			UpdateTS:   updateTS,
-		})
+	_, err = pgx.ForEachRow(rows, []any{&key.Token, &key.Unsigned, &key.Label, &scopes, &key.ExpireTS, &key.IPWhitelist, &key.CreateTS, &key.UpdateTS}, func() error {
+		key := key
+		key.AccountUID = accountUID
+		key.Scopes = stringsToScopes(scopes)
+		key.IPWhitelist = append([]string{}, key.IPWhitelist...)
+		result = append(result, key)
 		return nil
 	})
 
 	return result, err
 }
 
-func (s *Storage) SelectAccountUID(ctx context.Context, token entities.Token) (entities.AccountUID, error) {
-	sql := `SELECT account_uid FROM apikey WHERE token = $1`
+func (s *Storage) SelectAccountKey(ctx context.Context, token entities.Token) (*entities.Key, error) {
+	sql := `
+		SELECT token, account_uid, secret, unsigned, label, scopes, expire_ts, ip_whitelist, COALESCE(disabled, false), create_ts, COALESCE(update_ts, create_ts) 
+		FROM apikey 
+		WHERE token = $1
+	`
 
 	row := s.repo.QueryRow(ctx, sql, token)
 
-	var accountUID entities.AccountUID
+	var (
+		key    entities.Key
+		scopes []string
+	)
 
-	err := row.Scan(&accountUID)
+	err := row.Scan(&key.Token, &key.AccountUID, &key.Secret, &key.Unsigned, &key.Label, &scopes, &key.ExpireTS, &key.IPWhitelist, &key.Disabled, &key.CreateTS, &key.UpdateTS)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", apperror.ErrTokenNotFound
+			return nil, apperror.ErrTokenNotFound
 		}
-		return "", apperror.ErrRequestError
+		return nil, apperror.ErrRequestError
 	}
 
-	return accountUID, nil
+	key.Scopes = stringsToScopes(scopes)
+
+	return &key, nil
+}
+
+func scopesToStrings(scopes []entities.KeyScope) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		result = append(result, string(scope))
+	}
+	return result
+}
+
+func stringsToScopes(scopes []string) []entities.KeyScope {
+	result := make([]entities.KeyScope, 0, len(scopes))
+	for _, scope := range scopes {
+		result = append(result, entities.KeyScope(scope))
+	}
+	return result
 }
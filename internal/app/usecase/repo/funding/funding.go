@@ -0,0 +1,76 @@
+package funding
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"DemoExchange/internal/app/entities"
+)
+
+type Repository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) error
+}
+
+type Storage struct {
+	repo Repository
+}
+
+func New(repo Repository) *Storage {
+	return &Storage{
+		repo,
+	}
+}
+
+func (s *Storage) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.repo.WithTx(ctx, fn)
+}
+
+func (s *Storage) SelectFundings(ctx context.Context) ([]*entities.Funding, error) {
+	result := make([]*entities.Funding, 0)
+
+	sql := `
+		SELECT symbol, funding_ts, rate, price, settled_ts, update_ts 
+		FROM funding
+	`
+
+	rows, err := s.repo.Query(ctx, sql)
+	if err != nil {
+		return result, err
+	}
+
+	var funding entities.Funding
+
+	_, err = pgx.ForEachRow(rows, []any{&funding.Symbol, &funding.FundingTS, &funding.Rate, &funding.Price, &funding.SettledTS, &funding.UpdateTS}, func() error {
+		funding := funding
+		result = append(result, &funding)
+		return nil
+	})
+
+	return result, err
+}
+
+// SaveFunding stores the next funding time of the symbol, the last time paid is kept
+func (s *Storage) SaveFunding(ctx context.Context, funding *entities.Funding) error {
+	sql := `
+		INSERT INTO funding (symbol, funding_ts, rate, price, settled_ts, update_ts) 
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (symbol) DO UPDATE SET funding_ts = $2, rate = $3, price = $4, update_ts = $6
+	`
+
+	return s.repo.Exec(ctx, sql, funding.Symbol, funding.FundingTS, funding.Rate, funding.Price, funding.SettledTS, funding.UpdateTS)
+}
+
+// UpdateFundingSettled stores the last funding time paid of the symbol
+func (s *Storage) UpdateFundingSettled(ctx context.Context, funding *entities.Funding) error {
+	sql := `
+		INSERT INTO funding (symbol, funding_ts, rate, price, settled_ts, update_ts) 
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (symbol) DO UPDATE SET settled_ts = $5, update_ts = $6
+	`
+
+	return s.repo.Exec(ctx, sql, funding.Symbol, funding.FundingTS, funding.Rate, funding.Price, funding.SettledTS, funding.UpdateTS)
+}
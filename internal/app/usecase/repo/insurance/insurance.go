@@ -0,0 +1,51 @@
+package insurance
+
+import (
+	"DemoExchange/internal/app/entities"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) error
+}
+
+type Storage struct {
+	repo Repository
+}
+
+func New(repo Repository) *Storage {
+	return &Storage{
+		repo,
+	}
+}
+
+func (s *Storage) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.repo.WithTx(ctx, fn)
+}
+
+func (s *Storage) InsertInsuranceFund(ctx context.Context, fund *entities.InsuranceFund) error {
+	sql := `
+		INSERT INTO insurance_fund (fund_uid, exchange, account_uid, symbol, coin, amount, create_ts) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	return s.repo.Exec(ctx, sql, fund.FundUID, fund.Exchange, fund.AccountUID, fund.Symbol, fund.Coin, fund.Amount, fund.CreateTS)
+}
+
+func (s *Storage) SelectInsuranceFundBalance(ctx context.Context, exchange entities.Exchange, coin entities.Coin) (float64, error) {
+	sql := `
+		SELECT COALESCE(SUM(amount), 0) 
+		FROM insurance_fund 
+		WHERE exchange = $1 AND coin = $2
+	`
+
+	var balance float64
+	err := s.repo.QueryRow(ctx, sql, exchange, coin).Scan(&balance)
+
+	return balance, err
+}
@@ -5,12 +5,22 @@ import (
 	"errors"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"DemoExchange/internal/app/entities"
 )
 
+const (
+	codeUniqueViolation = "23505"
+	// clientOrderIDIndex keeps the client order id unique among the open orders of the account
+	clientOrderIDIndex = "order_client_order_id_idx"
+)
+
 //lint:ignore ST1005 strings capitalized
-var ErrOrderNotFound = errors.New("Order not found")
+var (
+	ErrOrderNotFound       = errors.New("Order not found")
+	ErrClientOrderIDExists = errors.New("Client order id exists")
+)
 
 type Repository interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
@@ -35,27 +45,83 @@ func (s *Storage) WithTx(ctx context.Context, fn func(ctx context.Context) error
 
 func (s *Storage) InsertOrder(ctx context.Context, order *entities.Order) error {
 	sql := `
-		INSERT INTO "order" (account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, create_ts, update_ts) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
-		RETURNING amount, price
+		INSERT INTO "order" (account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, client_order_id, create_ts, update_ts) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27)
+		RETURNING amount, price, order_id
 	`
-	row := s.repo.QueryRow(ctx, sql, order.AccountUID, order.OrderUID, order.Exchange, order.Symbol, order.Type, order.PositionSide, order.Side, order.Amount, order.Price, order.Fee, order.FeeCoin, order.ReduceOnly, order.Status, order.Leverage, order.CreateTS, order.UpdateTS)
+	row := s.repo.QueryRow(ctx, sql, order.AccountUID, order.OrderUID, order.Exchange, order.Symbol, order.Type, order.PositionSide, order.Side, order.Amount, order.Price, order.Fee, order.FeeCoin, order.ReduceOnly, order.Status, order.Leverage, order.StopPrice, order.Triggered, order.CallbackRate, order.ActivationPrice, order.GroupUID, order.TimeInForce, order.FilledAmount, order.AvgPrice, order.FeeType, order.RealizedPnl, order.ClientOrderID, order.CreateTS, order.UpdateTS)
 
-	return row.Scan(&order.Amount, &order.Price)
+	err := row.Scan(&order.Amount, &order.Price, &order.OrderID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == codeUniqueViolation && pgErr.ConstraintName == clientOrderIDIndex {
+			return ErrClientOrderIDExists
+		}
+	}
+
+	return err
 }
 
 func (s *Storage) SelectOrder(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, orderUID string) (*entities.Order, error) {
 	var order entities.Order
 
 	sql := `
-		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, create_ts, update_ts 
+		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, order_id, client_order_id, create_ts, update_ts 
 		FROM "order" 
 		WHERE exchange = $1 AND account_uid = $2 AND order_uid = $3
 	`
 
 	row := s.repo.QueryRow(ctx, sql, exchange, accountUID, orderUID)
 
-	err := row.Scan(&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.CreateTS, &order.UpdateTS)
+	err := row.Scan(&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.StopPrice, &order.Triggered, &order.CallbackRate, &order.ActivationPrice, &order.GroupUID, &order.TimeInForce, &order.FilledAmount, &order.AvgPrice, &order.FeeType, &order.RealizedPnl, &order.OrderID, &order.ClientOrderID, &order.CreateTS, &order.UpdateTS)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// SelectOrderByID finds the order by its numeric id used by the exchange compatible api
+func (s *Storage) SelectOrderByID(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, orderID int64) (*entities.Order, error) {
+	var order entities.Order
+
+	sql := `
+		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, order_id, client_order_id, create_ts, update_ts 
+		FROM "order" 
+		WHERE exchange = $1 AND account_uid = $2 AND order_id = $3
+	`
+
+	row := s.repo.QueryRow(ctx, sql, exchange, accountUID, orderID)
+
+	err := row.Scan(&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.StopPrice, &order.Triggered, &order.CallbackRate, &order.ActivationPrice, &order.GroupUID, &order.TimeInForce, &order.FilledAmount, &order.AvgPrice, &order.FeeType, &order.RealizedPnl, &order.OrderID, &order.ClientOrderID, &order.CreateTS, &order.UpdateTS)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// SelectOrderByClientID finds the latest order placed with the client order id
+func (s *Storage) SelectOrderByClientID(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, clientOrderID string) (*entities.Order, error) {
+	var order entities.Order
+
+	sql := `
+		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, order_id, client_order_id, create_ts, update_ts 
+		FROM "order" 
+		WHERE exchange = $1 AND account_uid = $2 AND client_order_id = $3
+		ORDER BY create_ts DESC
+		LIMIT 1
+	`
+
+	row := s.repo.QueryRow(ctx, sql, exchange, accountUID, clientOrderID)
+
+	err := row.Scan(&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.StopPrice, &order.Triggered, &order.CallbackRate, &order.ActivationPrice, &order.GroupUID, &order.TimeInForce, &order.FilledAmount, &order.AvgPrice, &order.FeeType, &order.RealizedPnl, &order.OrderID, &order.ClientOrderID, &order.CreateTS, &order.UpdateTS)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, ErrOrderNotFound
@@ -68,14 +134,14 @@ func (s *Storage) SelectOrder(ctx context.Context, exchange entities.Exchange, a
 
 func (s *Storage) UpdateOrder(ctx context.Context, order *entities.Order) error {
 	sql := `
-		UPDATE "order" SET status = $3, error = $4, price = $5, fee = $6, fee_coin = $7, update_ts = $8 WHERE account_uid = $1 AND order_uid = $2
+		UPDATE "order" SET status = $3, error = $4, price = $5, fee = $6, fee_coin = $7, stop_price = $8, triggered = $9, filled_amount = $10, avg_price = $11, fee_type = $12, realized_pnl = $13, update_ts = $14 WHERE account_uid = $1 AND order_uid = $2
 	`
-	return s.repo.Exec(ctx, sql, order.AccountUID, order.OrderUID, order.Status, order.Error, order.Price, order.Fee, order.FeeCoin, order.UpdateTS)
+	return s.repo.Exec(ctx, sql, order.AccountUID, order.OrderUID, order.Status, order.Error, order.Price, order.Fee, order.FeeCoin, order.StopPrice, order.Triggered, order.FilledAmount, order.AvgPrice, order.FeeType, order.RealizedPnl, order.UpdateTS)
 }
 
 func (s *Storage) SelectOrders(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, statuses []entities.OrderStatus, limit int) ([]*entities.Order, error) {
 	sql := `
-		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, create_ts, update_ts 
+		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, order_id, client_order_id, create_ts, update_ts 
 		FROM "order" 
 		WHERE exchange = $1 AND account_uid = $2 AND (status = ANY(string_to_array($3, ',')::text[]) OR $3 = '')
 		ORDER BY create_ts DESC
@@ -96,7 +162,7 @@ func (s *Storage) SelectOrders(ctx context.Context, exchange entities.Exchange,
 
 	orders := make([]*entities.Order, 0)
 
-	_, err = pgx.ForEachRow(rows, []any{&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.CreateTS, &order.UpdateTS}, func() error {
+	_, err = pgx.ForEachRow(rows, []any{&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.StopPrice, &order.Triggered, &order.CallbackRate, &order.ActivationPrice, &order.GroupUID, &order.TimeInForce, &order.FilledAmount, &order.AvgPrice, &order.FeeType, &order.RealizedPnl, &order.OrderID, &order.ClientOrderID, &order.CreateTS, &order.UpdateTS}, func() error {
 		order := order
 		orders = append(orders, &order)
 		return nil
@@ -107,16 +173,16 @@ func (s *Storage) SelectOrders(ctx context.Context, exchange entities.Exchange,
 
 func (s *Storage) SelectPendingOrders(ctx context.Context) ([]*entities.Order, error) {
 	sql := `
-		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, create_ts, update_ts 
+		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, order_id, client_order_id, create_ts, update_ts 
 		FROM "order" 
-		WHERE status IN ($1, $2)
+		WHERE status IN ($1, $2, $3)
 	`
 	var (
 		rows pgx.Rows
 		err  error
 	)
 
-	rows, err = s.repo.Query(ctx, sql, entities.OrderStatusNew, entities.OrderStatusPending)
+	rows, err = s.repo.Query(ctx, sql, entities.OrderStatusNew, entities.OrderStatusPending, entities.OrderStatusPartiallyFilled)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +192,7 @@ func (s *Storage) SelectPendingOrders(ctx context.Context) ([]*entities.Order, e
 		orders []*entities.Order
 	)
 
-	_, err = pgx.ForEachRow(rows, []any{&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.CreateTS, &order.UpdateTS}, func() error {
+	_, err = pgx.ForEachRow(rows, []any{&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.StopPrice, &order.Triggered, &order.CallbackRate, &order.ActivationPrice, &order.GroupUID, &order.TimeInForce, &order.FilledAmount, &order.AvgPrice, &order.FeeType, &order.RealizedPnl, &order.OrderID, &order.ClientOrderID, &order.CreateTS, &order.UpdateTS}, func() error {
 		order := order
 		orders = append(orders, &order)
 		return nil
@@ -137,18 +203,18 @@ func (s *Storage) SelectPendingOrders(ctx context.Context) ([]*entities.Order, e
 
 func (s *Storage) SelectPendingOrdersBySymbol(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, symbol *entities.Symbol) ([]*entities.Order, error) {
 	sql := `
-		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, create_ts, update_ts 
+		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, order_id, client_order_id, create_ts, update_ts 
 		FROM "order" 
 		WHERE exchange = $1 AND account_uid = $2 
 			AND (symbol = $3 OR $3 IS NULL)
-			AND status = $4
+			AND status IN ($4, $5)
 	`
 	var (
 		rows pgx.Rows
 		err  error
 	)
 
-	rows, err = s.repo.Query(ctx, sql, exchange, accountUID, symbol, entities.OrderStatusPending)
+	rows, err = s.repo.Query(ctx, sql, exchange, accountUID, symbol, entities.OrderStatusPending, entities.OrderStatusPartiallyFilled)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +223,7 @@ func (s *Storage) SelectPendingOrdersBySymbol(ctx context.Context, exchange enti
 
 	orders := make([]*entities.Order, 0)
 
-	_, err = pgx.ForEachRow(rows, []any{&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.CreateTS, &order.UpdateTS}, func() error {
+	_, err = pgx.ForEachRow(rows, []any{&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.StopPrice, &order.Triggered, &order.CallbackRate, &order.ActivationPrice, &order.GroupUID, &order.TimeInForce, &order.FilledAmount, &order.AvgPrice, &order.FeeType, &order.RealizedPnl, &order.OrderID, &order.ClientOrderID, &order.CreateTS, &order.UpdateTS}, func() error {
 		order := order
 		orders = append(orders, &order)
 		return nil
@@ -165,3 +231,65 @@ func (s *Storage) SelectPendingOrdersBySymbol(ctx context.Context, exchange enti
 
 	return orders, err
 }
+
+func (s *Storage) SelectGroupOrders(ctx context.Context, accountUID entities.AccountUID, groupUID string) ([]*entities.Order, error) {
+	sql := `
+		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, order_id, client_order_id, create_ts, update_ts 
+		FROM "order" 
+		WHERE account_uid = $1 AND group_uid = $2
+	`
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	rows, err = s.repo.Query(ctx, sql, accountUID, groupUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var order entities.Order
+
+	orders := make([]*entities.Order, 0)
+
+	_, err = pgx.ForEachRow(rows, []any{&order.AccountUID, &order.OrderUID, &order.Exchange, &order.Symbol, &order.Type, &order.PositionSide, &order.Side, &order.Amount, &order.Price, &order.Fee, &order.FeeCoin, &order.ReduceOnly, &order.Status, &order.Leverage, &order.StopPrice, &order.Triggered, &order.CallbackRate, &order.ActivationPrice, &order.GroupUID, &order.TimeInForce, &order.FilledAmount, &order.AvgPrice, &order.FeeType, &order.RealizedPnl, &order.OrderID, &order.ClientOrderID, &order.CreateTS, &order.UpdateTS}, func() error {
+		order := order
+		orders = append(orders, &order)
+		return nil
+	})
+
+	return orders, err
+}
+
+// SelectAccountVolume returns the traded volume of the account since the timestamp
+func (s *Storage) SelectAccountVolume(ctx context.Context, accountUID entities.AccountUID, from int64) (float64, error) {
+	sql := `
+		SELECT COALESCE(SUM(CASE WHEN filled_amount > 0 THEN filled_amount * avg_price WHEN status = $3 THEN amount * price ELSE 0 END), 0) 
+		FROM "order" 
+		WHERE account_uid = $1 AND create_ts >= $2
+	`
+
+	var volume float64
+
+	err := s.repo.QueryRow(ctx, sql, accountUID, from, entities.OrderStatusSuccess).Scan(&volume)
+
+	return volume, err
+}
+
+// ArchiveAccountOrders moves the account orders to the archive
+func (s *Storage) ArchiveAccountOrders(ctx context.Context, accountUID entities.AccountUID, archiveTS int64) error {
+	sql := `
+		INSERT INTO order_archive (account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, order_id, client_order_id, create_ts, update_ts, archive_ts)
+		SELECT account_uid, order_uid, exchange, symbol, type, position_side, side, amount, price, fee, fee_coin, reduce_only, status, leverage, stop_price, triggered, callback_rate, activation_price, group_uid, time_in_force, filled_amount, avg_price, fee_type, realized_pnl, order_id, client_order_id, create_ts, update_ts, $2 FROM "order" WHERE account_uid = $1
+	`
+
+	if err := s.repo.Exec(ctx, sql, accountUID, archiveTS); err != nil {
+		return err
+	}
+
+	sql = `
+		DELETE FROM "order" WHERE account_uid = $1
+	`
+
+	return s.repo.Exec(ctx, sql, accountUID)
+}
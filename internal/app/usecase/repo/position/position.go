@@ -32,36 +32,36 @@ func (s *Storage) WithTx(ctx context.Context, fn func(ctx context.Context) error
 
 func (s *Storage) InsertPosition(ctx context.Context, position *entities.Position) error {
 	sql := `
-		INSERT INTO "position" (account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, create_ts, update_ts) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		INSERT INTO "position" (account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, take_profit, stop_loss, create_ts, update_ts) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING amount, price, hold_amount
 	`
 
-	row := s.repo.QueryRow(ctx, sql, position.AccountUID, position.PositionUID, position.Exchange, position.Symbol, position.Mode, position.MarginType, position.Leverage, position.Side, position.Amount, position.Price, position.Margin, position.HoldAmount, position.CreateTS, position.UpdateTS)
+	row := s.repo.QueryRow(ctx, sql, position.AccountUID, position.PositionUID, position.Exchange, position.Symbol, position.Mode, position.MarginType, position.Leverage, position.Side, position.Amount, position.Price, position.Margin, position.HoldAmount, position.TakeProfit, position.StopLoss, position.CreateTS, position.UpdateTS)
 
 	return row.Scan(&position.Amount, &position.Price, &position.HoldAmount)
 }
 
 func (s *Storage) UpdatePosition(ctx context.Context, position *entities.Position) error {
 	sql := `
-		UPDATE "position" SET amount = $2, price = $3, margin = $4, hold_amount = $5, position_type = $6, leverage = $7, update_ts = $8 WHERE position_uid = $1
+		UPDATE "position" SET amount = $2, price = $3, margin = $4, hold_amount = $5, position_type = $6, leverage = $7, take_profit = $8, stop_loss = $9, update_ts = $10 WHERE position_uid = $1
 	`
 
-	return s.repo.Exec(ctx, sql, position.PositionUID, position.Amount, position.Price, position.Margin, position.HoldAmount, position.MarginType, position.Leverage, position.UpdateTS)
+	return s.repo.Exec(ctx, sql, position.PositionUID, position.Amount, position.Price, position.Margin, position.HoldAmount, position.MarginType, position.Leverage, position.TakeProfit, position.StopLoss, position.UpdateTS)
 }
 
 func (s *Storage) SelectPositionBySide(ctx context.Context, accountUID entities.AccountUID, symbol entities.Symbol, side entities.PositionSide) (*entities.Position, error) {
 	var position entities.Position
 
 	sql := `
-		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, create_ts, update_ts 
+		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, take_profit, stop_loss, create_ts, update_ts 
 		FROM "position" 
 		WHERE account_uid = $1 AND symbol = $2 AND side = $3
 	`
 
 	row := s.repo.QueryRow(ctx, sql, accountUID, symbol, side)
 
-	err := row.Scan(&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.CreateTS, &position.UpdateTS)
+	err := row.Scan(&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.TakeProfit, &position.StopLoss, &position.CreateTS, &position.UpdateTS)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, apperror.ErrPositionNotFound
@@ -74,7 +74,7 @@ func (s *Storage) SelectPositionBySide(ctx context.Context, accountUID entities.
 
 func (s *Storage) SelectPositionsBySymbol(ctx context.Context, accountUID entities.AccountUID, symbol entities.Symbol) (map[entities.PositionSide]*entities.Position, error) {
 	sql := `
-		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, create_ts, update_ts 
+		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, take_profit, stop_loss, create_ts, update_ts 
 		FROM "position" 
 		WHERE account_uid = $1 AND symbol = $2
 	`
@@ -88,7 +88,7 @@ func (s *Storage) SelectPositionsBySymbol(ctx context.Context, accountUID entiti
 
 	positions := make(map[entities.PositionSide]*entities.Position)
 
-	_, err = pgx.ForEachRow(rows, []any{&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.CreateTS, &position.UpdateTS}, func() error {
+	_, err = pgx.ForEachRow(rows, []any{&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.TakeProfit, &position.StopLoss, &position.CreateTS, &position.UpdateTS}, func() error {
 		position := position
 		positions[position.Side] = &position
 		return nil
@@ -99,7 +99,7 @@ func (s *Storage) SelectPositionsBySymbol(ctx context.Context, accountUID entiti
 
 func (s *Storage) SelectAccountPositions(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID) ([]*entities.Position, error) {
 	sql := `
-		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, create_ts, update_ts 
+		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, take_profit, stop_loss, create_ts, update_ts 
 		FROM "position" 
 		WHERE exchange = $1 AND account_uid = $2
 		ORDER BY create_ts DESC
@@ -118,7 +118,7 @@ func (s *Storage) SelectAccountPositions(ctx context.Context, exchange entities.
 
 	positions := make([]*entities.Position, 0)
 
-	_, err = pgx.ForEachRow(rows, []any{&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.CreateTS, &position.UpdateTS}, func() error {
+	_, err = pgx.ForEachRow(rows, []any{&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.TakeProfit, &position.StopLoss, &position.CreateTS, &position.UpdateTS}, func() error {
 		position := position
 		positions = append(positions, &position)
 		return nil
@@ -129,7 +129,7 @@ func (s *Storage) SelectAccountPositions(ctx context.Context, exchange entities.
 
 func (s *Storage) SelectAccountOpenPositions(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID) ([]*entities.Position, error) {
 	sql := `
-		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, create_ts, update_ts 
+		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, take_profit, stop_loss, create_ts, update_ts 
 		FROM "position" 
 		WHERE exchange = $1 AND account_uid = $2 AND amount <> 0
 	`
@@ -147,7 +147,36 @@ func (s *Storage) SelectAccountOpenPositions(ctx context.Context, exchange entit
 
 	positions := make([]*entities.Position, 0)
 
-	_, err = pgx.ForEachRow(rows, []any{&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.CreateTS, &position.UpdateTS}, func() error {
+	_, err = pgx.ForEachRow(rows, []any{&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.TakeProfit, &position.StopLoss, &position.CreateTS, &position.UpdateTS}, func() error {
+		position := position
+		positions = append(positions, &position)
+		return nil
+	})
+
+	return positions, err
+}
+
+func (s *Storage) SelectSymbolOpenPositions(ctx context.Context, exchange entities.Exchange, symbol entities.Symbol) ([]*entities.Position, error) {
+	sql := `
+		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, take_profit, stop_loss, create_ts, update_ts 
+		FROM "position" 
+		WHERE exchange = $1 AND symbol = $2 AND amount <> 0
+	`
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	rows, err = s.repo.Query(ctx, sql, exchange, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var position entities.Position
+
+	positions := make([]*entities.Position, 0)
+
+	_, err = pgx.ForEachRow(rows, []any{&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.TakeProfit, &position.StopLoss, &position.CreateTS, &position.UpdateTS}, func() error {
 		position := position
 		positions = append(positions, &position)
 		return nil
@@ -158,7 +187,7 @@ func (s *Storage) SelectAccountOpenPositions(ctx context.Context, exchange entit
 
 func (s *Storage) SelectOpenPositions(ctx context.Context) ([]*entities.Position, error) {
 	sql := `
-		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, create_ts, update_ts 
+		SELECT account_uid, position_uid, exchange, symbol, position_mode, position_type, leverage, side, amount, price, margin, hold_amount, take_profit, stop_loss, create_ts, update_ts 
 		FROM "position" 
 		WHERE amount <> 0
 	`
@@ -176,7 +205,7 @@ func (s *Storage) SelectOpenPositions(ctx context.Context) ([]*entities.Position
 
 	positions := make([]*entities.Position, 0)
 
-	_, err = pgx.ForEachRow(rows, []any{&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.CreateTS, &position.UpdateTS}, func() error {
+	_, err = pgx.ForEachRow(rows, []any{&position.AccountUID, &position.PositionUID, &position.Exchange, &position.Symbol, &position.Mode, &position.MarginType, &position.Leverage, &position.Side, &position.Amount, &position.Price, &position.Margin, &position.HoldAmount, &position.TakeProfit, &position.StopLoss, &position.CreateTS, &position.UpdateTS}, func() error {
 		position := position
 		positions = append(positions, &position)
 		return nil
@@ -184,3 +213,11 @@ func (s *Storage) SelectOpenPositions(ctx context.Context) ([]*entities.Position
 
 	return positions, err
 }
+
+func (s *Storage) DeleteAccountPositions(ctx context.Context, accountUID entities.AccountUID) error {
+	sql := `
+		DELETE FROM "position" WHERE account_uid = $1
+	`
+
+	return s.repo.Exec(ctx, sql, accountUID)
+}
@@ -0,0 +1,91 @@
+package trade
+
+import (
+	"DemoExchange/internal/app/entities"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) error
+}
+
+type Storage struct {
+	repo Repository
+}
+
+func New(repo Repository) *Storage {
+	return &Storage{
+		repo,
+	}
+}
+
+func (s *Storage) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.repo.WithTx(ctx, fn)
+}
+
+func (s *Storage) InsertTrade(ctx context.Context, trade *entities.Trade) error {
+	sql := `
+		INSERT INTO trade (account_uid, trade_uid, order_uid, exchange, symbol, side, position_side, price, amount, fee, fee_coin, is_maker, create_ts) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	return s.repo.Exec(ctx, sql, trade.AccountUID, trade.TradeUID, trade.OrderUID, trade.Exchange, trade.Symbol, trade.Side, trade.PositionSide, trade.Price, trade.Amount, trade.Fee, trade.FeeCoin, trade.IsMaker, trade.CreateTS)
+}
+
+func (s *Storage) SelectAccountTrades(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, filter entities.TradeFilter) ([]*entities.Trade, error) {
+	sql := `
+		SELECT account_uid, trade_uid, order_uid, exchange, symbol, side, position_side, price, amount, fee, fee_coin, is_maker, create_ts 
+		FROM trade 
+		WHERE exchange = $1 AND account_uid = $2
+			AND (order_uid::text = $3 OR $3 = '')
+			AND (symbol = $4 OR $4 = '')
+			AND (create_ts >= $5 OR $5 = 0)
+			AND (create_ts <= $6 OR $6 = 0)
+		ORDER BY create_ts DESC
+		LIMIT $7
+	`
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	rows, err = s.repo.Query(ctx, sql, exchange, accountUID, filter.OrderUID, filter.Symbol, filter.From, filter.To, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var trade entities.Trade
+
+	trades := make([]*entities.Trade, 0)
+
+	_, err = pgx.ForEachRow(rows, []any{&trade.AccountUID, &trade.TradeUID, &trade.OrderUID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.Price, &trade.Amount, &trade.Fee, &trade.FeeCoin, &trade.IsMaker, &trade.CreateTS}, func() error {
+		trade := trade
+		trades = append(trades, &trade)
+		return nil
+	})
+
+	return trades, err
+}
+
+// ArchiveAccountTrades moves the account trades to the archive
+func (s *Storage) ArchiveAccountTrades(ctx context.Context, accountUID entities.AccountUID, archiveTS int64) error {
+	sql := `
+		INSERT INTO trade_archive (account_uid, trade_uid, order_uid, exchange, symbol, side, position_side, price, amount, fee, fee_coin, is_maker, create_ts, archive_ts)
+		SELECT account_uid, trade_uid, order_uid, exchange, symbol, side, position_side, price, amount, fee, fee_coin, is_maker, create_ts, $2 FROM trade WHERE account_uid = $1
+	`
+
+	if err := s.repo.Exec(ctx, sql, accountUID, archiveTS); err != nil {
+		return err
+	}
+
+	sql = `
+		DELETE FROM trade WHERE account_uid = $1
+	`
+
+	return s.repo.Exec(ctx, sql, accountUID)
+}
@@ -30,16 +30,16 @@ func (s *Storage) WithTx(ctx context.Context, fn func(ctx context.Context) error
 
 func (s *Storage) InsertTransaction(ctx context.Context, transaction *entities.Transaction) error {
 	sql := `
-		INSERT INTO "transaction" (account_uid, transaction_uid, exchange, symbol, transaction_type, amount, create_ts) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO "transaction" (account_uid, transaction_uid, exchange, symbol, transaction_type, amount, price, fee_type, order_uid, create_ts) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
-	return s.repo.Exec(ctx, sql, transaction.AccountUID, transaction.TransactionID, transaction.Exchange, transaction.Symbol, transaction.TransactionType, transaction.Amount, transaction.CreateTS)
+	return s.repo.Exec(ctx, sql, transaction.AccountUID, transaction.TransactionID, transaction.Exchange, transaction.Symbol, transaction.TransactionType, transaction.Amount, transaction.Price, transaction.FeeType, transaction.OrderUID, transaction.CreateTS)
 }
 
 func (s *Storage) SelectAccountTransactions(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, filter entities.TransactionFilter) ([]*entities.Transaction, error) {
 	sql := `
-		SELECT account_uid, transaction_uid, exchange, symbol, transaction_type, amount, create_ts 
+		SELECT account_uid, transaction_uid, exchange, symbol, transaction_type, amount, price, fee_type, order_uid, create_ts 
 		FROM "transaction" 
 		WHERE exchange = $1 AND account_uid = $2
 			AND (transaction_type = $3 OR $3 = '')
@@ -62,7 +62,7 @@ func (s *Storage) SelectAccountTransactions(ctx context.Context, exchange entiti
 
 	transactions := make([]*entities.Transaction, 0)
 
-	_, err = pgx.ForEachRow(rows, []any{&transaction.AccountUID, &transaction.TransactionID, &transaction.Exchange, &transaction.Symbol, &transaction.TransactionType, &transaction.Amount, &transaction.CreateTS}, func() error {
+	_, err = pgx.ForEachRow(rows, []any{&transaction.AccountUID, &transaction.TransactionID, &transaction.Exchange, &transaction.Symbol, &transaction.TransactionType, &transaction.Amount, &transaction.Price, &transaction.FeeType, &transaction.OrderUID, &transaction.CreateTS}, func() error {
 		transaction := transaction
 		transactions = append(transactions, &transaction)
 		return nil
@@ -70,3 +70,21 @@ func (s *Storage) SelectAccountTransactions(ctx context.Context, exchange entiti
 
 	return transactions, err
 }
+
+// ArchiveAccountTransactions moves the account transactions to the archive
+func (s *Storage) ArchiveAccountTransactions(ctx context.Context, accountUID entities.AccountUID, archiveTS int64) error {
+	sql := `
+		INSERT INTO transaction_archive (account_uid, transaction_uid, exchange, symbol, transaction_type, amount, price, fee_type, order_uid, create_ts, archive_ts)
+		SELECT account_uid, transaction_uid, exchange, symbol, transaction_type, amount, price, fee_type, order_uid, create_ts, $2 FROM "transaction" WHERE account_uid = $1
+	`
+
+	if err := s.repo.Exec(ctx, sql, accountUID, archiveTS); err != nil {
+		return err
+	}
+
+	sql = `
+		DELETE FROM "transaction" WHERE account_uid = $1
+	`
+
+	return s.repo.Exec(ctx, sql, accountUID)
+}
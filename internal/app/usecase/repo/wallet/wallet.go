@@ -103,3 +103,11 @@ func (s *Storage) SetHoldCoin(ctx context.Context, wallet entities.Wallet) error
 
 	return s.repo.Exec(ctx, sql, wallet.Exchange, wallet.AccountUID, wallet.Balance.Coin, wallet.Balance.Hold, wallet.UpdateTS)
 }
+
+func (s *Storage) DeleteAccountWallets(ctx context.Context, accountUID entities.AccountUID) error {
+	sql := `
+		DELETE FROM wallet WHERE account_uid = $1
+	`
+
+	return s.repo.Exec(ctx, sql, accountUID)
+}
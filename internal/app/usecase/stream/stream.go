@@ -0,0 +1,94 @@
+package stream
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Logger interface {
+	Info(args ...interface{})
+}
+
+// Stream is a generic in-memory publisher, every subscriber of a key receives the values published for it
+type Stream[K comparable, V any] struct {
+	name        string
+	mu          sync.RWMutex
+	subscribers map[K]map[chan V]struct{}
+	size        int
+	log         Logger
+}
+
+// New creates a new instance of Stream, size is the buffer of a subscriber
+func New[K comparable, V any](size int, log Logger) *Stream[K, V] {
+	return &Stream[K, V]{
+		name:        fmt.Sprintf("%T", *new(V)),
+		mu:          sync.RWMutex{},
+		subscribers: make(map[K]map[chan V]struct{}),
+		size:        size,
+		log:         log,
+	}
+}
+
+// Subscribe returns the channel of the key values and the function closing it
+func (s *Stream[K, V]) Subscribe(key K) (<-chan V, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan V, s.size)
+
+	if _, ok := s.subscribers[key]; !ok {
+		s.subscribers[key] = make(map[chan V]struct{})
+	}
+	s.subscribers[key][ch] = struct{}{}
+
+	s.log.Info(fmt.Sprintf("Stream [%s] Subscribe: %v", s.name, key))
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			// the channel is already closed when the subscriber was dropped by Publish
+			if _, ok := s.subscribers[key][ch]; ok {
+				s.remove(key, ch)
+			}
+
+			s.log.Info(fmt.Sprintf("Stream [%s] Unsubscribe: %v", s.name, key))
+		})
+	}
+}
+
+// Publish sends the value to the subscribers of the key, a subscriber with a full buffer is dropped
+// and its channel is closed, so it never misses a value without knowing it
+func (s *Stream[K, V]) Publish(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers[key] {
+		select {
+		case ch <- value:
+		default:
+			s.remove(key, ch)
+			s.log.Info(fmt.Sprintf("Stream [%s] Publish: subscriber of %v is full, dropped", s.name, key))
+		}
+	}
+}
+
+// remove deletes the channel from the subscribers of the key and closes it, s.mu must be held
+func (s *Stream[K, V]) remove(key K, ch chan V) {
+	delete(s.subscribers[key], ch)
+	if len(s.subscribers[key]) == 0 {
+		delete(s.subscribers, key)
+	}
+	close(ch)
+}
+
+// HasSubscribers reports whether anybody listens to the key
+func (s *Stream[K, V]) HasSubscribers(key K) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.subscribers[key]) > 0
+}
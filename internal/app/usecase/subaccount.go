@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/entities"
+)
+
+// getMasterAccount returns the account if it may manage sub-accounts
+func (uc *Usecase) getMasterAccount(ctx context.Context, accountUID entities.AccountUID) (*entities.Account, error) {
+	account, err := uc.account.SelectAccountByUID(ctx, accountUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.IsSub() {
+		return nil, apperror.ErrNotMasterAccount
+	}
+
+	return account, nil
+}
+
+// getSubAccount returns the sub-account only if it belongs to the master
+func (uc *Usecase) getSubAccount(ctx context.Context, masterUID, accountUID entities.AccountUID) (*entities.Account, error) {
+	account, err := uc.account.SelectAccountByUID(ctx, accountUID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrAccountNotFound) {
+			return nil, apperror.ErrSubAccountNotFound
+		}
+		return nil, err
+	}
+
+	if account.MasterUID != masterUID {
+		return nil, apperror.ErrSubAccountNotFound
+	}
+
+	return account, nil
+}
+
+func (uc *Usecase) CreateSubAccount(ctx context.Context, masterUID entities.AccountUID, label string) (*entities.Account, *entities.Key, error) {
+	var (
+		account *entities.Account
+		key     *entities.Key
+	)
+
+	if err := uc.account.WithTx(ctx, func(ctx context.Context) error {
+		master, err := uc.getMasterAccount(ctx, masterUID)
+		if err != nil {
+			return err
+		}
+
+		subAccounts, err := uc.account.SelectSubAccounts(ctx, master.AccountUID)
+		if err != nil {
+			uc.log.Error(fmt.Sprintf("CreateSubAccount:SelectSubAccounts [master_uid: %s] error: %v", master.AccountUID, err))
+			return err
+		}
+
+		if len(subAccounts) >= uc.cfg.SubAccountLimit {
+			return apperror.ErrSubAccountLimitExceeded
+		}
+
+		account = entities.NewSubAccount(master, label)
+
+		err = uc.account.InsertAccount(ctx, account)
+		if err != nil {
+			uc.log.Error(fmt.Sprintf("CreateSubAccount:InsertAccount [%+v] error: %v", *account, err))
+			return err
+		}
+
+		key, err = uc.createAccountKey(ctx, account.AccountUID, entities.KeySettings{})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	uc.log.Info(fmt.Sprintf("CreateSubAccount: [master_uid: %s, account_uid: %s]", masterUID, account.AccountUID))
+
+	return account, key, nil
+}
+
+func (uc *Usecase) CreateSubAccountToken(ctx context.Context, masterUID, accountUID entities.AccountUID) (*entities.Key, error) {
+	var key *entities.Key
+
+	if err := uc.apikey.WithTx(ctx, func(ctx context.Context) error {
+		account, err := uc.getSubAccount(ctx, masterUID, accountUID)
+		if err != nil {
+			return err
+		}
+
+		key, err = uc.createAccountKey(ctx, account.AccountUID, entities.KeySettings{})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	uc.log.Info(fmt.Sprintf("CreateSubAccountToken: [master_uid: %s, account_uid: %s]", masterUID, accountUID))
+
+	return key, nil
+}
+
+func (uc *Usecase) SubAccountsList(ctx context.Context, masterUID entities.AccountUID) ([]*entities.Account, error) {
+	master, err := uc.getMasterAccount(ctx, masterUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.account.SelectSubAccounts(ctx, master.AccountUID)
+}
+
+// SubAccountTransfer moves funds between the master and one of its sub-accounts or between two of its sub-accounts,
+// an empty account uid stands for the master
+func (uc *Usecase) SubAccountTransfer(ctx context.Context, masterUID, from, to entities.AccountUID, exchange entities.Exchange, coin entities.Coin, amount float64) error {
+	if !exchange.IsValid() {
+		return apperror.ErrExchangeIsNotValid
+	}
+
+	if amount <= 0 {
+		return apperror.ErrAmountIsNotValid
+	}
+
+	if from == "" {
+		from = masterUID
+	}
+
+	if to == "" {
+		to = masterUID
+	}
+
+	if from == to {
+		return apperror.ErrTransferIsNotValid
+	}
+
+	if _, err := uc.getMasterAccount(ctx, masterUID); err != nil {
+		return err
+	}
+
+	for _, accountUID := range []entities.AccountUID{from, to} {
+		if accountUID == masterUID {
+			continue
+		}
+
+		if _, err := uc.getSubAccount(ctx, masterUID, accountUID); err != nil {
+			return err
+		}
+	}
+
+	return uc.transfer(ctx, from, exchange, to, exchange, coin, amount)
+}
+
+// SubAccountBalances returns the balances of the master and of every sub-account with their sum
+func (uc *Usecase) SubAccountBalances(ctx context.Context, exchange entities.Exchange, masterUID entities.AccountUID) (*entities.AccountBalances, error) {
+	subAccounts, err := uc.SubAccountsList(ctx, masterUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.AccountBalances{
+		Total:    make(entities.Balances),
+		Accounts: make(map[entities.AccountUID]entities.Balances, len(subAccounts)+1),
+	}
+
+	accountUIDs := []entities.AccountUID{masterUID}
+	for _, account := range subAccounts {
+		accountUIDs = append(accountUIDs, account.AccountUID)
+	}
+
+	for _, accountUID := range accountUIDs {
+		balances, err := uc.GetBalances(ctx, exchange, accountUID)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Accounts[accountUID] = balances
+		result.Total.Add(balances)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"DemoExchange/internal/app/entities"
+	"context"
+	"fmt"
+)
+
+func (uc *Usecase) TradesList(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, filter entities.TradeFilter) ([]*entities.Trade, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 100
+	}
+
+	trades, err := uc.trade.SelectAccountTrades(ctx, exchange, accountUID, filter)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("TradesList:SelectAccountTrades [AccountUID: %s] error: %v", accountUID, err))
+		return nil, err
+	}
+
+	return trades, nil
+}
+
+func (uc *Usecase) saveTrade(ctx context.Context, trade *entities.Trade) error {
+	return uc.trade.InsertTrade(ctx, trade)
+}
@@ -8,20 +8,39 @@ import (
 	"DemoExchange/internal/app/usecase/orders"
 	"DemoExchange/internal/app/usecase/repo/account"
 	"DemoExchange/internal/app/usecase/repo/apikey"
+	"DemoExchange/internal/app/usecase/repo/funding"
+	"DemoExchange/internal/app/usecase/repo/insurance"
 	"DemoExchange/internal/app/usecase/repo/order"
 	"DemoExchange/internal/app/usecase/repo/position"
+	"DemoExchange/internal/app/usecase/repo/trade"
 	"DemoExchange/internal/app/usecase/repo/transaction"
 	"DemoExchange/internal/app/usecase/repo/wallet"
+	"DemoExchange/internal/app/usecase/stream"
 	"context"
 )
 
-const lenBufferOrders = 100
+const (
+	lenBufferOrders   = 100
+	lenBufferUserData = 100
+)
 
 var exchanges = []entities.Exchange{entities.ExchangeSpot, entities.ExchangeFutures}
 
 type Config struct {
-	KeyLimit    int
-	MaxBalances map[entities.Coin]float64
+	KeyLimit          int
+	SubAccountLimit   int
+	UnsignedKeysUntil int64
+
+	WalletLimits        entities.WalletLimits
+	ServiceWalletLimits map[string]entities.WalletLimits
+
+	FeeTiers           []entities.FeeTier
+	FeeTierAutoUpgrade bool
+
+	MarginBrackets       entities.MarginBrackets
+	SymbolMarginBrackets map[entities.Symbol]entities.MarginBrackets
+	LiquidationFee       float64
+	CollateralHaircuts   map[entities.Coin]float64
 }
 
 type Usecase struct {
@@ -33,16 +52,26 @@ type Usecase struct {
 	order       OrderStorage
 	position    PositionStorage
 	transaction TransactionStorage
+	trade       TradeStorage
+	insurance   InsuranceStorage
+	funding     FundingStorage
 
 	cacheOrders    Cache[string, *entities.Order]
 	cachePositions Cache[string, *entities.Position]
+	listenKeys     Cache[string, *entities.ListenKey]
+	signatures     *signatures
+	feeTiers       *feeTiers
+
+	userData   Stream[entities.AccountUID, *entities.UserEvent]
+	chBalances chan walletKey
 
 	chOrders    chan *orders.Order
 	chPositions chan *entities.Position
 
-	tickers Tickers
-	markets Markets
-	log     Logger
+	tickers   Tickers
+	markets   Markets
+	orderbook Orderbook
+	log       Logger
 }
 
 func (uc *Usecase) GetMarketWithContext(ctx context.Context, exchange string, market string) (markets.Market, error) {
@@ -54,7 +83,7 @@ func (uc *Usecase) GetTickerWithContext(ctx context.Context, exchange string, ma
 }
 
 // New creates new usecase
-func New(cfg Config, repo Connection, tickers Tickers, markets Markets, log Logger) *Usecase {
+func New(cfg Config, repo Connection, tickers Tickers, markets Markets, orderbook Orderbook, log Logger) *Usecase {
 	return &Usecase{
 		cfg: cfg,
 
@@ -64,15 +93,25 @@ func New(cfg Config, repo Connection, tickers Tickers, markets Markets, log Logg
 		order:       order.New(repo),
 		position:    position.New(repo),
 		transaction: transaction.New(repo),
+		trade:       trade.New(repo),
+		insurance:   insurance.New(repo),
+		funding:     funding.New(repo),
 
 		cacheOrders:    cache.New[string, *entities.Order](log),
 		cachePositions: cache.New[string, *entities.Position](log),
+		listenKeys:     cache.New[string, *entities.ListenKey](log),
+		signatures:     newSignatures(),
+		feeTiers:       newFeeTiers(),
+
+		userData:   stream.New[entities.AccountUID, *entities.UserEvent](lenBufferUserData, log),
+		chBalances: make(chan walletKey, lenBufferUserData),
 
 		chOrders:    make(chan *orders.Order, lenBufferOrders),
 		chPositions: make(chan *entities.Position),
 
-		tickers: tickers,
-		markets: markets,
-		log:     log,
+		tickers:   tickers,
+		markets:   markets,
+		orderbook: orderbook,
+		log:       log,
 	}
 }
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"DemoExchange/internal/app/apperror"
+	"DemoExchange/internal/app/entities"
+)
+
+// userDataTimeout batches the balance changes, so a balance is read once the transaction changing it is committed
+const userDataTimeout = time.Second
+
+type walletKey struct {
+	exchange   entities.Exchange
+	accountUID entities.AccountUID
+}
+
+func (uc *Usecase) CreateListenKey(ctx context.Context, accountUID entities.AccountUID) *entities.ListenKey {
+	key := entities.NewListenKey(accountUID)
+	uc.listenKeys.Set(key.Key, key)
+
+	return key
+}
+
+func (uc *Usecase) GetListenKey(ctx context.Context, listenKey string) (*entities.ListenKey, error) {
+	key, ok := uc.listenKeys.Get(listenKey)
+	if !ok || key.IsExpired() {
+		return nil, apperror.ErrListenKeyNotFound
+	}
+
+	return key, nil
+}
+
+// KeepAliveListenKey extends the lifetime of the listen key of the account
+func (uc *Usecase) KeepAliveListenKey(ctx context.Context, accountUID entities.AccountUID, listenKey string) (*entities.ListenKey, error) {
+	key, err := uc.GetListenKey(ctx, listenKey)
+	if err != nil || key.AccountUID != accountUID {
+		return nil, apperror.ErrListenKeyNotFound
+	}
+
+	key = &entities.ListenKey{
+		Key:        key.Key,
+		AccountUID: key.AccountUID,
+		ExpireTS:   entities.TS() + entities.ListenKeyTTL.Milliseconds(),
+	}
+	uc.listenKeys.Set(key.Key, key)
+
+	return key, nil
+}
+
+func (uc *Usecase) DeleteListenKey(ctx context.Context, accountUID entities.AccountUID, listenKey string) error {
+	key, ok := uc.listenKeys.Get(listenKey)
+	if !ok || key.AccountUID != accountUID {
+		return apperror.ErrListenKeyNotFound
+	}
+
+	uc.listenKeys.Delete(listenKey)
+
+	return nil
+}
+
+func (uc *Usecase) SubscribeUserData(ctx context.Context, accountUID entities.AccountUID) (<-chan *entities.UserEvent, func()) {
+	return uc.userData.Subscribe(accountUID)
+}
+
+// UserDataSnapshot returns the open orders, the positions and the balances of the account
+func (uc *Usecase) UserDataSnapshot(ctx context.Context, accountUID entities.AccountUID) (*entities.UserSnapshot, error) {
+	snapshot := &entities.UserSnapshot{
+		Orders:    make([]*entities.Order, 0),
+		Positions: make([]*entities.Position, 0),
+		Balances:  make(map[entities.Exchange]entities.Balances, len(exchanges)),
+	}
+
+	statuses := []entities.OrderStatus{entities.OrderStatusNew, entities.OrderStatusPending, entities.OrderStatusPartiallyFilled}
+
+	for _, exchange := range exchanges {
+		orders, err := uc.OrdersList(ctx, exchange, accountUID, statuses, 0)
+		if err != nil {
+			uc.log.Error(fmt.Sprintf("UserDataSnapshot:OrdersList [account_uid: %s, exchange: %s] error: %v", accountUID, exchange, err))
+			return nil, err
+		}
+		snapshot.Orders = append(snapshot.Orders, orders...)
+
+		balances, err := uc.GetBalances(ctx, exchange, accountUID)
+		if err != nil {
+			uc.log.Error(fmt.Sprintf("UserDataSnapshot:GetBalances [account_uid: %s, exchange: %s] error: %v", accountUID, exchange, err))
+			return nil, err
+		}
+		snapshot.Balances[exchange] = balances
+	}
+
+	positions, err := uc.PositionsList(ctx, entities.ExchangeFutures, accountUID)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("UserDataSnapshot:PositionsList [account_uid: %s] error: %v", accountUID, err))
+		return nil, err
+	}
+	snapshot.Positions = positions
+
+	return snapshot, nil
+}
+
+func (uc *Usecase) publishOrder(order *entities.Order) {
+	if !uc.userData.HasSubscribers(order.AccountUID) {
+		return
+	}
+
+	o := *order
+	o.Trade = nil
+	uc.userData.Publish(order.AccountUID, entities.NewUserEvent(entities.UserEventOrder, order.Exchange, &o))
+}
+
+func (uc *Usecase) publishPosition(position *entities.Position) {
+	if !uc.userData.HasSubscribers(position.AccountUID) {
+		return
+	}
+
+	p := *position
+	uc.userData.Publish(position.AccountUID, entities.NewUserEvent(entities.UserEventPosition, position.Exchange, &p))
+}
+
+// notifyBalance marks the wallet as changed, the balances are published by ProcessUserData
+func (uc *Usecase) notifyBalance(exchange entities.Exchange, accountUID entities.AccountUID) {
+	if !uc.userData.HasSubscribers(accountUID) {
+		return
+	}
+
+	select {
+	case uc.chBalances <- walletKey{exchange, accountUID}:
+	default:
+		uc.log.Info(fmt.Sprintf("notifyBalance: buffer is full [account_uid: %s, exchange: %s]", accountUID, exchange))
+	}
+}
+
+// ProcessUserData publishes the balances of the changed wallets and drops the expired listen keys
+func (uc *Usecase) ProcessUserData(ctx context.Context) {
+	ticker := time.NewTicker(userDataTimeout)
+	defer ticker.Stop()
+
+	changed := make(map[walletKey]struct{})
+
+	for {
+		select {
+		case <-ctx.Done():
+			uc.log.Error(fmt.Sprintf("ProcessUserData:Done error: %v", ctx.Err()))
+			return
+
+		case key := <-uc.chBalances:
+			changed[key] = struct{}{}
+
+		case <-ticker.C:
+			for key := range changed {
+				balances, err := uc.GetBalances(ctx, key.exchange, key.accountUID)
+				if err != nil {
+					uc.log.Error(fmt.Sprintf("ProcessUserData:GetBalances [account_uid: %s, exchange: %s] error: %v", key.accountUID, key.exchange, err))
+					continue
+				}
+
+				uc.userData.Publish(key.accountUID, entities.NewUserEvent(entities.UserEventBalance, key.exchange, balances))
+			}
+			changed = make(map[walletKey]struct{})
+
+			for _, key := range uc.listenKeys.List() {
+				if key.IsExpired() {
+					uc.listenKeys.Delete(key.Key)
+				}
+			}
+		}
+	}
+}
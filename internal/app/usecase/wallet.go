@@ -11,19 +11,34 @@ import (
 
 var muWallet = &sync.RWMutex{}
 
-var initialBalance = map[entities.Exchange]entities.Balance{
-	entities.ExchangeSpot: {
-		Coin:  "USDT",
-		Total: 3000,
-	},
-	entities.ExchangeFutures: {
-		Coin:  "USDT",
-		Total: 3000,
-	},
+func (uc *Usecase) walletLimits(service string) entities.WalletLimits {
+	return uc.cfg.WalletLimits.Override(uc.cfg.ServiceWalletLimits[service])
 }
 
-func (uc *Usecase) GetInitialBalance(exchange entities.Exchange) entities.Balance {
-	return initialBalance[exchange]
+func (uc *Usecase) GetInitialBalances(service string, exchange entities.Exchange) map[entities.Coin]float64 {
+	return uc.walletLimits(service).Initial[exchange]
+}
+
+func (uc *Usecase) getBalanceLimit(service string, coin entities.Coin) float64 {
+	return uc.walletLimits(service).Max[coin]
+}
+
+// appendInitialBalances credits the initial balances of the service on every exchange
+func (uc *Usecase) appendInitialBalances(ctx context.Context, service string, accountUID entities.AccountUID) error {
+	for _, exchange := range exchanges {
+		for coin, amount := range uc.GetInitialBalances(service, exchange) {
+			balance := entities.Balance{
+				Coin:  coin,
+				Total: amount,
+			}
+
+			if err := uc.AppendBalance(ctx, exchange, accountUID, balance); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
 
 func (uc *Usecase) GetBalances(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID) (entities.Balances, error) {
@@ -56,11 +71,23 @@ func (uc *Usecase) GetBalances(ctx context.Context, exchange entities.Exchange,
 		return nil, err
 	}
 
+	tickers, err := uc.tickers.GetTickers(exchange.Name())
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("GetBalances:GetTickers [exchange: %s] error: %v", exchange.Name(), err))
+		return nil, err
+	}
+
 	for _, p := range positions {
 		coins := p.Symbol.GetCoins()
 
+		if ticker, ok := tickers[p.Symbol.String()]; ok {
+			p.CalcMarginBalance(markPrice(ticker))
+			p.CalcMaintMargin(uc.marginBrackets(p.Symbol))
+		}
+
 		if balance, ok := balances[coins.CoinBase]; ok {
 			balance.InitialMargin += p.Margin
+			balance.MaintMargin += p.MaintMargin
 			balance.WalletBalance += p.Margin        // available + margin
 			balance.MarginBalance += p.MarginBalance // margin + pnl
 			balance.UnrealisedPnl += p.UnrealisedPnl
@@ -68,55 +95,78 @@ func (uc *Usecase) GetBalances(ctx context.Context, exchange entities.Exchange,
 		}
 	}
 
+	for coin, balance := range balances {
+		if uc.isCollateral(coin) {
+			continue
+		}
+
+		for collateralCoin, value := range uc.collateralValues(tickers, balances, coin) {
+			collateral := balances[collateralCoin]
+			collateral.CollateralValue = value
+			balances[collateralCoin] = collateral
+
+			balance.CollateralValue += value
+		}
+		balances[coin] = balance
+	}
+
 	return balances, nil
 }
 
 func (uc *Usecase) Deposit(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin, amount float64) (float64, error) {
+	if amount <= 0 {
+		return 0, apperror.ErrAmountIsNotValid
+	}
+
+	account, err := uc.account.SelectAccountByUID(ctx, accountUID)
+	if err != nil {
+		uc.log.Error(fmt.Sprintf("Deposit:SelectAccountByUID [account_uid: %s] error: %v", accountUID, err))
+		return 0, err
+	}
+
+	maxBalance := uc.getBalanceLimit(account.Service, coin)
+	if maxBalance == 0 {
+		return 0, apperror.ErrAppendBalanceCoinNotAllowed
+	}
+
 	muWallet.Lock()
 	defer muWallet.Unlock()
 
-	err := uc.wallet.WithTx(ctx, func(ctx context.Context) error {
-		// 	maxBalance := uc.getBalanceLimit(coin)
-		// 	if maxBalance == 0 {
-		// 		return apperror.ErrAppendBalanceCoinNotAllowed
-		// 	}
-
+	err = uc.wallet.WithTx(ctx, func(ctx context.Context) error {
 		wallet := entities.Wallet{
 			Exchange:   exchange,
 			AccountUID: accountUID,
-			Balance: entities.Balance{
-				Coin:  coin,
-				Total: amount,
-			},
-			UpdateTS: entities.TS(),
 		}
 
-		// balances, err := uc.wallet.SelectBalances(ctx, wallet)
-		// if err != nil {
-		// 	return err
-		// }
-
-		// balance, ok := balances[coin]
-		// if ok {
-		// 	amount += balance.Total
-		// }
+		balances, err := uc.wallet.SelectBalances(ctx, wallet)
+		if err != nil {
+			return err
+		}
 
-		// 	if balanceTotal+amount > maxBalance {
-		// 		amount = maxBalance - balanceTotal
-		// 	}
+		// the deposit is cut down to the limit of the balance
+		if balance, ok := balances[coin]; ok && balance.Total+amount > maxBalance {
+			amount = maxBalance - balance.Total
+		} else if amount > maxBalance {
+			amount = maxBalance
+		}
 
-		// 	if amount <= 0 {
-		// 		return apperror.ErrBalanceLimitExceeded
-		// 	}
+		if amount <= 0 {
+			return apperror.ErrBalanceLimitExceeded
+		}
 
-		// wallet.Balance.Coin = coin
-		// wallet.Balance.Total = amount
-		// wallet.UpdateTS = entities.TS()
+		wallet.Balance.Coin = coin
+		wallet.Balance.Total = amount
+		wallet.UpdateTS = entities.TS()
 
 		return uc.wallet.AppendTotalCoin(ctx, wallet)
 	})
+	if err != nil {
+		return 0, err
+	}
+
+	uc.notifyBalance(exchange, accountUID)
 
-	return amount, err
+	return amount, nil
 }
 
 func (uc *Usecase) Withdraw(ctx context.Context, exchange entities.Exchange, accountUID entities.AccountUID, coin entities.Coin, amount float64) error {
@@ -124,29 +174,88 @@ func (uc *Usecase) Withdraw(ctx context.Context, exchange entities.Exchange, acc
 	defer muWallet.Unlock()
 
 	return uc.wallet.WithTx(ctx, func(ctx context.Context) error {
+		available, err := uc.availableBalance(ctx, exchange, accountUID, coin)
+		if err != nil {
+			return err
+		}
+
+		if available < amount {
+			return apperror.ErrInsufficientFunds
+		}
+
 		wallet := entities.Wallet{
 			Exchange:   exchange,
 			AccountUID: accountUID,
 		}
-		balances, err := uc.wallet.SelectBalances(ctx, wallet)
-		if err != nil {
+		wallet.Balance.Coin = coin
+		wallet.Balance.Total = amount
+		wallet.UpdateTS = entities.TS()
+
+		if err := uc.wallet.SubtractTotalCoin(ctx, wallet); err != nil {
 			return err
 		}
 
-		balance, ok := balances[coin]
-		if !ok {
-			return apperror.ErrBalanceNotFound
+		uc.notifyBalance(exchange, accountUID)
+
+		return nil
+	})
+}
+
+// Transfer moves the amount of the coin from the wallet of one exchange to another, only the available balance can be moved
+func (uc *Usecase) Transfer(ctx context.Context, accountUID entities.AccountUID, coin entities.Coin, from, to entities.Exchange, amount float64) error {
+	if from == to || !from.IsValid() || !to.IsValid() {
+		return apperror.ErrExchangeIsNotValid
+	}
+
+	if amount <= 0 {
+		return apperror.ErrAmountIsNotValid
+	}
+
+	return uc.transfer(ctx, accountUID, from, accountUID, to, coin, amount)
+}
+
+// transfer moves the available amount of the coin from the wallet of one account and exchange to another
+// and records both sides in the ledger in one transaction
+func (uc *Usecase) transfer(ctx context.Context, fromAccount entities.AccountUID, fromExchange entities.Exchange, toAccount entities.AccountUID, toExchange entities.Exchange, coin entities.Coin, amount float64) error {
+	muWallet.Lock()
+	defer muWallet.Unlock()
+
+	return uc.wallet.WithTx(ctx, func(ctx context.Context) error {
+		available, err := uc.availableBalance(ctx, fromExchange, fromAccount, coin)
+		if err != nil {
+			return err
 		}
 
-		if balance.Total-amount < 0 {
+		if available < amount {
 			return apperror.ErrInsufficientFunds
 		}
 
-		wallet.Balance.Coin = coin
-		wallet.Balance.Total = amount
-		wallet.UpdateTS = entities.TS()
+		balance := entities.Balance{
+			Coin:  coin,
+			Total: amount,
+		}
+
+		if err := uc.SubtractBalance(ctx, fromExchange, fromAccount, balance); err != nil {
+			return err
+		}
+
+		if err := uc.AppendBalance(ctx, toExchange, toAccount, balance); err != nil {
+			return err
+		}
+
+		transactionOut := entities.NewTransaction(fromAccount, fromExchange, "", entities.TransactionTypeTransferOut, -amount)
+		if err := uc.AppendTransaction(ctx, transactionOut); err != nil {
+			uc.log.Error(fmt.Sprintf("transfer:AppendTransaction [%+v] error: %v", *transactionOut, err))
+			return err
+		}
+
+		transactionIn := entities.NewTransaction(toAccount, toExchange, "", entities.TransactionTypeTransferIn, amount)
+		if err := uc.AppendTransaction(ctx, transactionIn); err != nil {
+			uc.log.Error(fmt.Sprintf("transfer:AppendTransaction [%+v] error: %v", *transactionIn, err))
+			return err
+		}
 
-		return uc.wallet.SubtractTotalCoin(ctx, wallet)
+		return nil
 	})
 }
 
@@ -188,6 +297,8 @@ func (uc *Usecase) SetHoldBalance(ctx context.Context, exchange entities.Exchang
 		return err
 	}
 
+	uc.notifyBalance(exchange, accountUID)
+
 	return nil
 }
 
@@ -204,6 +315,8 @@ func (uc *Usecase) AppendBalance(ctx context.Context, exchange entities.Exchange
 		return err
 	}
 
+	uc.notifyBalance(exchange, accountUID)
+
 	return nil
 }
 
@@ -220,9 +333,7 @@ func (uc *Usecase) SubtractBalance(ctx context.Context, exchange entities.Exchan
 		return err
 	}
 
+	uc.notifyBalance(exchange, accountUID)
+
 	return nil
 }
-
-// func (uc *Usecase) getBalanceLimit(coin entities.Coin) float64 {
-// 	return uc.cfg.MaxBalances[coin]
-// }
@@ -18,10 +18,28 @@ type Config struct {
 		CertCrt            string   `yaml:"certCrt"`
 		CertKey            string   `yaml:"certKey"`
 		AllowServiceTokens []string `yaml:"allowServiceTokens"`
+		TrustedProxies     []string `yaml:"trustedProxies"`
 	} `yaml:"webserver"`
 	Service struct {
-		KeyLimit int `yaml:"keyLimit"`
+		KeyLimit        int `yaml:"keyLimit"`
+		SubAccountLimit int `yaml:"subAccountLimit"`
+		// UnsignedKeysUntil is the time in ms the keys issued before signing may send unsigned requests until
+		UnsignedKeysUntil int64 `yaml:"unsignedKeysUntil"`
 	} `yaml:"service"`
+	Balances struct {
+		Balances `yaml:",inline"`
+		Services map[string]Balances `yaml:"services"`
+	} `yaml:"balances"`
+	FeeTiers struct {
+		AutoUpgrade bool      `yaml:"autoUpgrade"`
+		Tiers       []FeeTier `yaml:"tiers"`
+	} `yaml:"feeTiers"`
+	Margin struct {
+		LiquidationFee float64                    `yaml:"liquidationFee"`
+		Haircuts       map[string]float64         `yaml:"haircuts"`
+		Brackets       []MarginBracket            `yaml:"brackets"`
+		Symbols        map[string][]MarginBracket `yaml:"symbols"`
+	} `yaml:"margin"`
 	DB struct {
 		Host         string `yaml:"host"`
 		Port         string `yaml:"port"`
@@ -42,6 +60,29 @@ type Config struct {
 	} `yaml:"logger"`
 }
 
+type Balances struct {
+	Initial map[string]map[string]float64 `yaml:"initial"`
+	Max     map[string]float64            `yaml:"max"`
+}
+
+type FeeTier struct {
+	Name    string   `yaml:"name"`
+	Volume  float64  `yaml:"volume"`
+	Spot    FeeRates `yaml:"spot"`
+	Futures FeeRates `yaml:"futures"`
+}
+
+type FeeRates struct {
+	Maker float64 `yaml:"maker"`
+	Taker float64 `yaml:"taker"`
+}
+
+type MarginBracket struct {
+	Notional    float64 `yaml:"notional"`
+	MaxLeverage int32   `yaml:"maxLeverage"`
+	MMR         float64 `yaml:"mmr"`
+}
+
 func GetConfig() (*Config, error) {
 	filename := os.Getenv("CONFIG_FILE")
 	if filename == "" {
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00011, nil)
+}
+
+func Up00011(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "order" ADD stop_price numeric(16, 8) NULL DEFAULT 0;
+		ALTER TABLE "order" ADD triggered bool NULL DEFAULT false;
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00012, nil)
+}
+
+func Up00012(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "position" ADD take_profit numeric(16, 8) NULL DEFAULT 0;
+		ALTER TABLE "position" ADD stop_loss numeric(16, 8) NULL DEFAULT 0;
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
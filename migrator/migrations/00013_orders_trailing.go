@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00013, nil)
+}
+
+func Up00013(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "order" ADD callback_rate numeric(16, 8) NULL DEFAULT 0;
+		ALTER TABLE "order" ADD activation_price numeric(16, 8) NULL DEFAULT 0;
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
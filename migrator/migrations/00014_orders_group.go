@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00014, nil)
+}
+
+func Up00014(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "order" ADD group_uid varchar NULL DEFAULT ''::character varying;
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
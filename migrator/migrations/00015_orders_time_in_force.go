@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00015, nil)
+}
+
+func Up00015(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "order" ADD time_in_force varchar NULL DEFAULT 'GTC'::character varying;
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
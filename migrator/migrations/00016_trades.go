@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00016, nil)
+}
+
+func Up00016(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "order" ADD filled_amount numeric(16, 8) NULL DEFAULT 0;
+		ALTER TABLE "order" ADD avg_price numeric(16, 8) NULL DEFAULT 0;
+
+		CREATE TABLE trade (
+			trade_uid uuid NOT NULL,
+			order_uid uuid NOT NULL,
+			account_uid uuid NOT NULL,
+			exchange varchar NOT NULL,
+			symbol varchar NOT NULL,
+			side varchar NOT NULL,
+			position_side varchar NULL DEFAULT ''::character varying,
+			price numeric(16, 8) NOT NULL,
+			amount numeric(16, 8) NOT NULL,
+			fee numeric(16, 8) NULL DEFAULT 0,
+			fee_coin varchar NULL DEFAULT ''::character varying,
+			is_maker bool NULL DEFAULT false,
+			create_ts int8 NOT NULL,
+			CONSTRAINT trade_pk PRIMARY KEY (trade_uid)
+		);
+
+		ALTER TABLE trade ADD CONSTRAINT trade_account_fk FOREIGN KEY (account_uid) REFERENCES account(account_uid) ON DELETE CASCADE;
+		CREATE INDEX trade_account_uid_idx ON trade (account_uid);
+		CREATE INDEX trade_order_uid_idx ON trade (order_uid);
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00017, nil)
+}
+
+func Up00017(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "order" ADD fee_type varchar NULL DEFAULT ''::character varying;
+		ALTER TABLE "transaction" ADD fee_type varchar NULL DEFAULT ''::character varying;
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00018, nil)
+}
+
+func Up00018(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE account ADD fee_tier varchar NULL DEFAULT ''::character varying;
+		ALTER TABLE account ADD fee_tier_pinned bool NULL DEFAULT false;
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
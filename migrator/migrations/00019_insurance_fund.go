@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00019, nil)
+}
+
+func Up00019(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE insurance_fund (
+			fund_uid uuid NOT NULL,
+			exchange varchar NOT NULL,
+			account_uid uuid NOT NULL,
+			symbol varchar NOT NULL,
+			coin varchar NOT NULL,
+			amount numeric(16, 8) NOT NULL,
+			create_ts int8 NOT NULL,
+			CONSTRAINT insurance_fund_pk PRIMARY KEY (fund_uid)
+		);
+
+		CREATE INDEX insurance_fund_exchange_idx ON insurance_fund (exchange);
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00020, nil)
+}
+
+func Up00020(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "transaction" ADD price numeric(16, 8) NULL DEFAULT 0;
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
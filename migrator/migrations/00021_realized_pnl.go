@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00021, nil)
+}
+
+func Up00021(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "order" ADD realized_pnl numeric(16, 8) NULL DEFAULT 0;
+		ALTER TABLE "transaction" ADD order_uid varchar NULL DEFAULT ''::character varying;
+
+		CREATE INDEX transaction_order_uid_idx ON "transaction" (order_uid);
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00022, nil)
+}
+
+func Up00022(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE account ADD master_uid varchar NULL DEFAULT ''::character varying;
+		ALTER TABLE account ADD label varchar NULL DEFAULT ''::character varying;
+
+		CREATE INDEX account_master_uid_idx ON account (master_uid);
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00023, nil)
+}
+
+func Up00023(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE order_archive (LIKE "order" INCLUDING DEFAULTS);
+		ALTER TABLE order_archive ADD archive_ts int8 NOT NULL;
+		CREATE INDEX order_archive_account_uid_idx ON order_archive (account_uid);
+
+		CREATE TABLE trade_archive (LIKE trade INCLUDING DEFAULTS);
+		ALTER TABLE trade_archive ADD archive_ts int8 NOT NULL;
+		CREATE INDEX trade_archive_account_uid_idx ON trade_archive (account_uid);
+
+		CREATE TABLE transaction_archive (LIKE "transaction" INCLUDING DEFAULTS);
+		ALTER TABLE transaction_archive ADD archive_ts int8 NOT NULL;
+		CREATE INDEX transaction_archive_account_uid_idx ON transaction_archive (account_uid);
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00024, nil)
+}
+
+func Up00024(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE "order" ADD order_id bigserial NOT NULL;
+		CREATE UNIQUE INDEX order_order_id_idx ON "order" (order_id);
+
+		ALTER TABLE "order" ADD client_order_id varchar NOT NULL DEFAULT ''::character varying;
+		CREATE INDEX order_client_order_id_idx ON "order" (account_uid, client_order_id) WHERE client_order_id <> '';
+
+		ALTER TABLE order_archive ADD order_id int8 NULL;
+		ALTER TABLE order_archive ADD client_order_id varchar NULL DEFAULT ''::character varying;
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+
+	"DemoExchange/internal/app/pkg/hash"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00025, nil)
+}
+
+// Up00025 gives every existing key a secret and marks it unsigned,
+// such a key works without signatures only within the grace period of the config until its secret is rotated
+func Up00025(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE apikey ADD secret varchar NOT NULL DEFAULT ''::character varying;
+		ALTER TABLE apikey ADD unsigned bool NOT NULL DEFAULT false;
+
+		UPDATE apikey SET unsigned = true;
+	`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT token FROM apikey`)
+	if err != nil {
+		return err
+	}
+
+	var tokens []string
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			rows.Close()
+			return err
+		}
+		tokens = append(tokens, token)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		secret, err := hash.GenRandom(32)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE apikey SET secret = $2 WHERE token = $1`, token, secret); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
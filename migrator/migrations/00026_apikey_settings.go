@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00026, nil)
+}
+
+func Up00026(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE apikey ADD label varchar NOT NULL DEFAULT ''::character varying;
+		ALTER TABLE apikey ADD scopes text[] NOT NULL DEFAULT '{read,spot,futures,wallet}';
+		ALTER TABLE apikey ADD expire_ts int8 NOT NULL DEFAULT 0;
+		ALTER TABLE apikey ADD ip_whitelist text[] NOT NULL DEFAULT '{}';
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
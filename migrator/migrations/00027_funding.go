@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00027, nil)
+}
+
+func Up00027(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE funding (
+			symbol varchar NOT NULL,
+			funding_ts int8 NOT NULL,
+			rate float8 NOT NULL DEFAULT 0,
+			price float8 NOT NULL DEFAULT 0,
+			settled_ts int8 NOT NULL DEFAULT 0,
+			update_ts int8 NOT NULL,
+			CONSTRAINT funding_pk PRIMARY KEY (symbol)
+		);
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(Up00028, nil)
+}
+
+func Up00028(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		DROP INDEX order_client_order_id_idx;
+		CREATE UNIQUE INDEX order_client_order_id_idx ON "order" (exchange, account_uid, client_order_id)
+			WHERE client_order_id <> '' AND status IN ('new', 'pending', 'partially_filled');
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}